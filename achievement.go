@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// An achievement is reached by performing its objective the required number of times over the user's lifetime.
+// Unlike daily quests, the progress never resets and the rewards can only be claimed once.
+type Achievement struct {
+	Id        int           `json:"id"`
+	Objective int           `json:"objective"`
+	Required  int           `json:"required"`
+	Rewards   []Transaction `json:"rewards"`
+}
+
+func UnMarshalAchievementsJson() ([]Achievement, error) {
+	var data map[string][]Achievement
+
+	err := json.Unmarshal([]byte(achievementsJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["achievements"], nil
+}
+
+// Find the achievement with the given ID. The bool will be false if the achievement doesn't exist.
+func FindAchievement(dc *DataCache, id int) (Achievement, bool) {
+	for _, achievement := range dc.Achievements {
+		if achievement.Id == id {
+			return achievement, true
+		}
+	}
+
+	return Achievement{}, false
+}
+
+// Returns the IDs of the achievements with the given objective.
+func AchievementsByObjective(dc *DataCache, objective int) []int {
+	ids := make([]int, 0)
+
+	for _, achievement := range dc.Achievements {
+		if achievement.Objective == objective {
+			ids = append(ids, achievement.Id)
+		}
+	}
+
+	return ids
+}
+
+// The user's progress on an achievement. Achievements without a progress row have a count of 0.
+type AchievementProgress struct {
+	AchievementId int  `json:"achievementId"`
+	Count         int  `json:"count"`
+	IsClaimed     bool `json:"isClaimed"`
+}
+
+// Will subscribe to the events that progress each achievement objective.
+func SubscribeAchievements(bus *EventBus, dc *DataCache) {
+	progress := func(objective int, amount func(event Event) int) EventHandler {
+		return func(ctx context.Context, tx pgx.Tx, event Event) error {
+			if n := amount(event); n > 0 {
+				return IncAchievementProgress(ctx, tx, dc, event.User(), objective, n)
+			}
+
+			return nil
+		}
+	}
+
+	bus.Subscribe(UnitSummonEvent{}, progress(ACHIEVEMENT_SUMMON_UNITS, func(event Event) int {
+		return len(event.(UnitSummonEvent).Templates)
+	}))
+
+	bus.Subscribe(UnitLevelUpEvent{}, progress(ACHIEVEMENT_LEVEL_UP_UNITS, func(event Event) int {
+		return event.(UnitLevelUpEvent).Levels
+	}))
+
+	bus.Subscribe(CampaignBattleEvent{}, progress(ACHIEVEMENT_WIN_BATTLES, func(event Event) int {
+		if event.(CampaignBattleEvent).Win {
+			return 1
+		}
+		return 0
+	}))
+
+	bus.Subscribe(CampaignCollectEvent{}, progress(ACHIEVEMENT_COLLECT_CAMPAIGN, func(event Event) int {
+		return 1
+	}))
+
+	bus.Subscribe(ChatSendEvent{}, progress(ACHIEVEMENT_SEND_CHAT, func(event Event) int {
+		return 1
+	}))
+}
+
+// Will increase the progress of every achievement with the objective. The progress row is inserted if missing.
+func IncAchievementProgress(ctx context.Context, tx pgx.Tx, dc *DataCache, userId uuid.UUID, objective int, amount int) error {
+	for _, achievementId := range AchievementsByObjective(dc, objective) {
+		query := `INSERT INTO achievement_progress (user_id, achievement_id, count) VALUES ($1, $2, $3)
+				  ON CONFLICT (user_id, achievement_id) DO UPDATE SET count = achievement_progress.count + $3`
+
+		_, err := tx.Exec(ctx, query, userId, achievementId, amount)
+		if err != nil {
+			return fmt.Errorf("fail to upsert achievement_progress row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Returns the user's progress on every achievement.
+func FindAchievementProgress(ctx context.Context, db *pgxpool.Pool, dc *DataCache, userId uuid.UUID) ([]AchievementProgress, error) {
+	progress := make([]AchievementProgress, len(dc.Achievements))
+	index := make(map[int]int)
+
+	for i, achievement := range dc.Achievements {
+		progress[i] = AchievementProgress{AchievementId: achievement.Id}
+		index[achievement.Id] = i
+	}
+
+	query := "SELECT achievement_id, count, claimed_at IS NOT NULL FROM achievement_progress WHERE user_id = $1"
+	rows, err := db.Query(ctx, query, userId)
+	if err != nil {
+		return progress, fmt.Errorf("fail to query achievement_progress table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p AchievementProgress
+
+		if err := rows.Scan(&p.AchievementId, &p.Count, &p.IsClaimed); err != nil {
+			return progress, fmt.Errorf("fail to scan achievement_progress row: %w", err)
+		}
+
+		if i, ok := index[p.AchievementId]; ok {
+			progress[i] = p
+		}
+	}
+
+	return progress, rows.Err()
+}
+
+// Will mark the achievement as claimed if its count has reached the required count.
+// Returns false if the achievement is not reached or was already claimed.
+func ClaimAchievement(ctx context.Context, tx pgx.Tx, userId uuid.UUID, achievement Achievement) (bool, error) {
+	query := `UPDATE achievement_progress SET claimed_at = $1
+			  WHERE (user_id = $2 AND achievement_id = $3 AND count >= $4 AND claimed_at IS NULL)`
+
+	tag, err := tx.Exec(ctx, query, time.Now(), userId, achievement.Id, achievement.Required)
+	if err != nil {
+		return false, fmt.Errorf("fail to update achievement_progress row: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// Will record every published event for analytics. Events are inserted in the transaction of the action
+// so actions that roll back are never recorded.
+func SubscribeAnalytics(bus *EventBus) {
+	bus.SubscribeAll(InsertGameEvent)
+}
+
+// Will insert the event into the game_events table. The event is stored as json.
+func InsertGameEvent(ctx context.Context, tx pgx.Tx, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("fail to marshal event: %w", err)
+	}
+
+	query := "INSERT INTO game_events (user_id, name, data, created_at) VALUES ($1, $2, $3, $4)"
+	if _, err := tx.Exec(ctx, query, event.User(), event.Name(), string(data), time.Now()); err != nil {
+		return fmt.Errorf("fail to insert game_events row: %w", err)
+	}
+
+	return nil
+}
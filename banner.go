@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// A summon banner. Banners without a start or end time are always active.
+// When a featured template's rarity is rolled, the featured rate is the percent chance of getting a featured template.
+// A summon of the pity rarity or higher is guaranteed once the pity counter reaches the pity value.
+type Banner struct {
+	Id           int        `json:"id"`
+	Name         string     `json:"name"`
+	StartsAt     *time.Time `json:"startsAt"`
+	EndsAt       *time.Time `json:"endsAt"`
+	Featured     []int      `json:"featured"`
+	FeaturedRate int        `json:"featuredRate"`
+	Pity         int        `json:"pity"`
+	PityRarity   int        `json:"pityRarity"`
+}
+
+func UnMarshalBannersJson() ([]Banner, error) {
+	var data map[string][]Banner
+
+	err := json.Unmarshal([]byte(bannersJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["banners"], nil
+}
+
+// Find the banner with the given ID. The bool will be false if the banner doesn't exist.
+func FindBanner(dc *DataCache, id int) (Banner, bool) {
+	for _, banner := range dc.Banners {
+		if banner.Id == id {
+			return banner, true
+		}
+	}
+
+	return Banner{}, false
+}
+
+// Returns the banner ID from the banner query parameter or the standard banner if the parameter is missing.
+func ParseBannerId(params url.Values) (int, error) {
+	param := params.Get("banner")
+	if param == "" {
+		return SUMMON_STANDARD_BANNER, nil
+	}
+
+	id, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, errors.New("banner should be an integer")
+	}
+
+	return id, nil
+}
+
+// Returns every banner that is active at the given time.
+func ActiveBanners(dc *DataCache, now time.Time) []Banner {
+	banners := make([]Banner, 0)
+
+	for _, banner := range dc.Banners {
+		if banner.IsActive(now) {
+			banners = append(banners, banner)
+		}
+	}
+
+	return banners
+}
+
+func (b Banner) IsActive(now time.Time) bool {
+	if b.StartsAt != nil && now.Before(*b.StartsAt) {
+		return false
+	}
+
+	if b.EndsAt != nil && !now.Before(*b.EndsAt) {
+		return false
+	}
+
+	return true
+}
+
+// Will roll a template on the banner. The pity is the number of summons since the last summon of the pity rarity.
+// Rolls below the min rarity are raised to it. Returns the template ID and the rarity.
+func (b Banner) Roll(dc *DataCache, rng Rng, pity int, minRarity int) (int, int) {
+	rates := AvailableSummonRates(dc)
+
+	weights := make([]int, len(rates))
+	for i, rate := range rates {
+		weights[i] = rate.Weight
+	}
+
+	rarity := rates[rng.Weighted(weights)].Rarity
+
+	if rarity < minRarity && len(TemplatesByRarity(dc, minRarity)) > 0 {
+		rarity = minRarity
+	}
+
+	if pity+1 >= b.Pity && rarity < b.PityRarity && len(TemplatesByRarity(dc, b.PityRarity)) > 0 {
+		rarity = b.PityRarity
+	}
+
+	featured := make([]int, 0)
+	for _, id := range b.Featured {
+		if template, ok := FindUnitTemplate(dc, id); ok && template.Rarity == rarity {
+			featured = append(featured, id)
+		}
+	}
+
+	if len(featured) > 0 && rng.Chance(b.FeaturedRate) {
+		return featured[rng.Int(0, len(featured)-1)], rarity
+	}
+
+	templates := TemplatesByRarity(dc, rarity)
+
+	return templates[rng.Int(0, len(templates)-1)], rarity
+}
+
+// The number of summons a user has made on a banner since their last summon of the pity rarity.
+type BannerPity struct {
+	BannerId int `json:"bannerId"`
+	Count    int `json:"count"`
+}
+
+// Will update the pity after a summon of the given rarity. The count is reset if the pity rarity was summoned.
+func (p *BannerPity) Update(ctx context.Context, tx pgx.Tx, userId uuid.UUID, banner Banner, rarity int) error {
+	if rarity >= banner.PityRarity {
+		p.Count = 0
+	} else {
+		p.Count++
+	}
+
+	query := `INSERT INTO banner_pity (user_id, banner_id, count) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id, banner_id) DO UPDATE SET count = EXCLUDED.count`
+
+	_, err := tx.Exec(ctx, query, userId, p.BannerId, p.Count)
+	if err != nil {
+		return fmt.Errorf("fail to upsert banner_pity row: %w", err)
+	}
+
+	return nil
+}
+
+// Will find the user's pity on the banner for update. The count is 0 if the user never summoned on the banner.
+func FindBannerPityLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, bannerId int) (BannerPity, error) {
+	pity := BannerPity{BannerId: bannerId}
+
+	query := "SELECT count FROM banner_pity WHERE (user_id = $1 AND banner_id = $2) FOR UPDATE"
+	err := tx.QueryRow(ctx, query, userId, bannerId).Scan(&pity.Count)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return pity, fmt.Errorf("fail to query banner_pity row: %w", err)
+	}
+
+	return pity, nil
+}
+
+// Will find the user's pity on every banner they have summoned on.
+func FindBannerPities(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) ([]BannerPity, error) {
+	pities := make([]BannerPity, 0)
+
+	rows, err := db.Query(ctx, "SELECT banner_id, count FROM banner_pity WHERE user_id = $1", userId)
+	if err != nil {
+		return pities, fmt.Errorf("fail to query banner_pity table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pity BannerPity
+
+		if err := rows.Scan(&pity.BannerId, &pity.Count); err != nil {
+			return pities, fmt.Errorf("fail to scan banner_pity row: %w", err)
+		}
+
+		pities = append(pities, pity)
+	}
+
+	return pities, rows.Err()
+}
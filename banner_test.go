@@ -0,0 +1,134 @@
+package main_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/cdrpl/idlemon-server"
+)
+
+func TestBannerRollPity(t *testing.T) {
+	dc := idlemonServer.DataCache
+	banner, _ := FindBanner(dc, SUMMON_STANDARD_BANNER)
+	rng := CreateSeededRng(1)
+
+	// the roll that reaches the pity value is raised to the pity rarity
+	for i := 0; i < 1000; i++ {
+		_, rarity := banner.Roll(dc, rng, banner.Pity-1, RARITY_COMMON)
+
+		if rarity < banner.PityRarity {
+			t.Fatalf("expect rarity of at least %v once pity is reached, receive: %v", banner.PityRarity, rarity)
+		}
+	}
+
+	// the min rarity raises the roll the same way
+	for i := 0; i < 1000; i++ {
+		_, rarity := banner.Roll(dc, rng, 0, RARITY_RARE)
+
+		if rarity < RARITY_RARE {
+			t.Fatalf("expect rarity of at least %v, receive: %v", RARITY_RARE, rarity)
+		}
+	}
+}
+
+func TestBannerRollFeatured(t *testing.T) {
+	dc := idlemonServer.DataCache
+	rng := CreateSeededRng(2)
+
+	template, _ := FindUnitTemplate(dc, 1)
+	banner := Banner{Featured: []int{template.ID}, FeaturedRate: 100, Pity: 1000, PityRarity: template.Rarity}
+
+	// every roll of the featured rarity should return the featured template
+	rolled := 0
+	for i := 0; i < 1000; i++ {
+		id, rarity := banner.Roll(dc, rng, 0, template.Rarity)
+		if rarity != template.Rarity {
+			continue
+		}
+
+		rolled++
+
+		if id != template.ID {
+			t.Fatalf("expect featured template %v, receive: %v", template.ID, id)
+		}
+	}
+
+	if rolled == 0 {
+		t.Fatalf("expect the featured rarity %v to be rolled at least once", template.Rarity)
+	}
+
+	// a featured rate of 0 should still return other templates of the rarity
+	banner.FeaturedRate = 0
+	others := 0
+
+	for i := 0; i < 1000; i++ {
+		if id, rarity := banner.Roll(dc, rng, 0, template.Rarity); rarity == template.Rarity && id != template.ID {
+			others++
+		}
+	}
+
+	if len(TemplatesByRarity(dc, template.Rarity)) > 1 && others == 0 {
+		t.Errorf("expect templates other than %v to be rolled with a featured rate of 0", template.ID)
+	}
+}
+
+func TestBannerIsActive(t *testing.T) {
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	banner := Banner{StartsAt: &start, EndsAt: &end}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		active bool
+	}{
+		{"before start", start.Add(-time.Nanosecond), false},
+		{"at start", start, true},
+		{"before end", end.Add(-time.Nanosecond), true},
+		{"at end", end, false},
+		{"after end", end.Add(time.Hour), false},
+	}
+
+	for _, test := range tests {
+		if active := banner.IsActive(test.now); active != test.active {
+			t.Errorf("%v: expect active %v, receive: %v", test.name, test.active, active)
+		}
+	}
+
+	// a banner without a start or end time is always active
+	if !(Banner{}).IsActive(end.AddDate(10, 0, 0)) {
+		t.Errorf("expect banner without start or end time to be active")
+	}
+}
+
+// Fails once the limited banner schedule runs out within the next 30 days, more banners should be added to banners.json.
+func TestBannerSchedule(t *testing.T) {
+	limited := make([]Banner, 0)
+
+	for _, banner := range idlemonServer.DataCache.Banners {
+		if banner.StartsAt != nil && banner.EndsAt != nil {
+			limited = append(limited, banner)
+		}
+	}
+
+	if len(limited) == 0 {
+		t.Fatalf("expect at least one limited banner")
+	}
+
+	sort.Slice(limited, func(i, j int) bool {
+		return limited[i].StartsAt.Before(*limited[j].StartsAt)
+	})
+
+	// every limited banner should start when the previous one ends
+	for i := 1; i < len(limited); i++ {
+		if !limited[i].StartsAt.Equal(*limited[i-1].EndsAt) {
+			t.Errorf("expect banner %v to start when banner %v ends", limited[i].Id, limited[i-1].Id)
+		}
+	}
+
+	last := limited[len(limited)-1]
+	if last.EndsAt.Before(time.Now().AddDate(0, 0, 30)) {
+		t.Errorf("expect the limited banner schedule to last at least 30 more days, the last banner ends at %v", last.EndsAt)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"sort"
+)
+
+// A unit taking part in a battle simulation.
+type BattleUnit struct {
+	Template  int            `json:"template"`
+	Type      int            `json:"type"`
+	Level     int            `json:"level"`
+	Hp        int            `json:"hp"`
+	MaxHp     int            `json:"maxHp"`
+	Atk       int            `json:"atk"`
+	Def       int            `json:"def"`
+	Spd       int            `json:"spd"`
+	Skills    []Skill        `json:"-"`
+	Cooldowns []int          `json:"-"` // Rounds until each skill can be used again.
+	Effects   []BattleEffect `json:"-"`
+}
+
+// A skill effect applied to a battle unit. Rounds is the remaining duration, 0 lasts the whole battle.
+type BattleEffect struct {
+	Stat   int
+	Amount int
+	Rounds int
+}
+
+// The outcome of a battle simulation.
+type BattleResult struct {
+	Win       bool `json:"win"`
+	Rounds    int  `json:"rounds"`
+	TeamSize  int  `json:"teamSize"`
+	Survivors int  `json:"survivors"`
+}
+
+// Create a battle unit using the unit's computed stats and the template's skills.
+func CreateBattleUnit(dc *DataCache, unit Unit) BattleUnit {
+	stats := CalcUnitStats(dc, unit)
+	template, _ := FindUnitTemplate(dc, unit.Template)
+
+	return BattleUnit{
+		Template:  unit.Template,
+		Type:      template.TypeID,
+		Level:     unit.Level,
+		Hp:        stats.Hp,
+		MaxHp:     stats.Hp,
+		Atk:       stats.Atk,
+		Def:       stats.Def,
+		Spd:       stats.Spd,
+		Skills:    template.Skills,
+		Cooldowns: make([]int, len(template.Skills)),
+	}
+}
+
+func (u *BattleUnit) IsAlive() bool {
+	return u.Hp > 0
+}
+
+// Returns the value of the stat after applying the unit's effects. Effects cannot lower a stat below 0.
+func (u *BattleUnit) Stat(stat int) int {
+	value := u.Atk
+	if stat == SKILL_STAT_DEF {
+		value = u.Def
+	}
+
+	percent := 100
+	for _, effect := range u.Effects {
+		if effect.Stat == stat {
+			percent += effect.Amount
+		}
+	}
+
+	if percent < 0 {
+		percent = 0
+	}
+
+	return value * percent / 100
+}
+
+// Will deal a basic attack to the target.
+func (u *BattleUnit) Attack(adv TypeAdvantage, rng Rng, target *BattleUnit) {
+	u.Damage(adv, rng, target, 100)
+}
+
+// Will deal damage to the target based on the attacker's atk and the target's def.
+// The multiplier is a percent of the attacker's atk. The type advantage can raise the damage or cause a miss.
+// Minimum damage on a hit is 1.
+func (u *BattleUnit) Damage(adv TypeAdvantage, rng Rng, target *BattleUnit, multiplier int) {
+	if rng.Chance(adv.AttackMissChance(u.Type, target.Type)) {
+		return
+	}
+
+	damage := u.Stat(SKILL_STAT_ATK)*multiplier/100 - target.Stat(SKILL_STAT_DEF)/2
+	damage = damage * adv.DamagePercent(u.Type, target.Type) / 100
+	if damage < 1 {
+		damage = 1
+	}
+
+	target.Hp -= damage
+	if target.Hp < 0 {
+		target.Hp = 0
+	}
+}
+
+// Will restore hp to the unit without going over the max hp.
+func (u *BattleUnit) Heal(amount int) {
+	u.Hp += amount
+	if u.Hp > u.MaxHp {
+		u.Hp = u.MaxHp
+	}
+}
+
+// Returns the index of the first active skill that is off cooldown or -1 if there are none.
+func (u *BattleUnit) ReadySkill() int {
+	for i, skill := range u.Skills {
+		if skill.Type == SKILL_ACTIVE && u.Cooldowns[i] == 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Will use the skill on its targets. Passive skill effects last the whole battle.
+func (u *BattleUnit) UseSkill(adv TypeAdvantage, rng Rng, skill Skill, allies []BattleUnit, enemies []BattleUnit) {
+	for _, target := range skill.Targets(u, allies, enemies) {
+		if skill.Damage > 0 {
+			u.Damage(adv, rng, target, skill.Damage)
+		}
+
+		if skill.Heal > 0 {
+			target.Heal(u.Stat(SKILL_STAT_ATK) * skill.Heal / 100)
+		}
+
+		for _, effect := range skill.Effects {
+			rounds := effect.Duration
+			if skill.Type == SKILL_PASSIVE {
+				rounds = 0
+			}
+
+			target.Effects = append(target.Effects, BattleEffect{Stat: effect.Stat, Amount: effect.Amount, Rounds: rounds})
+		}
+	}
+}
+
+// Will lower skill cooldowns and remove effects that have run out.
+func (u *BattleUnit) EndRound() {
+	for i := range u.Cooldowns {
+		if u.Cooldowns[i] > 0 {
+			u.Cooldowns[i]--
+		}
+	}
+
+	effects := u.Effects[:0]
+	for _, effect := range u.Effects {
+		if effect.Rounds == 0 {
+			effects = append(effects, effect)
+		} else if effect.Rounds--; effect.Rounds > 0 {
+			effects = append(effects, effect)
+		}
+	}
+
+	u.Effects = effects
+}
+
+// Will simulate a battle between the allies and enemies. Passive skills are applied before the first round.
+// Every round each living unit acts once in order of speed, using its first ready active skill or a basic attack.
+// The allies win if every enemy is defeated before BATTLE_MAX_ROUNDS.
+func SimulateBattle(adv TypeAdvantage, rng Rng, allies []BattleUnit, enemies []BattleUnit) BattleResult {
+	result := BattleResult{TeamSize: len(allies)}
+
+	type combatant struct {
+		unit  *BattleUnit
+		enemy bool
+	}
+
+	order := make([]combatant, 0, len(allies)+len(enemies))
+	for i := range allies {
+		order = append(order, combatant{unit: &allies[i]})
+	}
+	for i := range enemies {
+		order = append(order, combatant{unit: &enemies[i], enemy: true})
+	}
+
+	// faster units act first, allies act first on ties
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].unit.Spd > order[j].unit.Spd
+	})
+
+	teams := func(c combatant) (friends []BattleUnit, foes []BattleUnit) {
+		if c.enemy {
+			return enemies, allies
+		}
+		return allies, enemies
+	}
+
+	for _, c := range order {
+		friends, foes := teams(c)
+
+		for _, skill := range c.unit.Skills {
+			if skill.Type == SKILL_PASSIVE {
+				c.unit.UseSkill(adv, rng, skill, friends, foes)
+			}
+		}
+	}
+
+	for result.Rounds < BATTLE_MAX_ROUNDS && CountAlive(allies) > 0 && CountAlive(enemies) > 0 {
+		result.Rounds++
+
+		for _, c := range order {
+			if !c.unit.IsAlive() {
+				continue
+			}
+
+			friends, foes := teams(c)
+
+			if CountAlive(foes) == 0 {
+				break
+			}
+
+			if i := c.unit.ReadySkill(); i != -1 {
+				c.unit.UseSkill(adv, rng, c.unit.Skills[i], friends, foes)
+				c.unit.Cooldowns[i] = c.unit.Skills[i].Cooldown
+			} else {
+				c.unit.Attack(adv, rng, LowestHpTarget(foes))
+			}
+		}
+
+		for _, c := range order {
+			c.unit.EndRound()
+		}
+	}
+
+	result.Survivors = CountAlive(allies)
+	result.Win = result.Survivors > 0 && CountAlive(enemies) == 0
+
+	return result
+}
+
+// Returns the number of units that are still alive.
+func CountAlive(units []BattleUnit) int {
+	count := 0
+
+	for i := range units {
+		if units[i].IsAlive() {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Returns the living unit with the lowest hp or nil if every unit is defeated.
+func LowestHpTarget(units []BattleUnit) *BattleUnit {
+	var target *BattleUnit
+
+	for i := range units {
+		if units[i].IsAlive() && (target == nil || units[i].Hp < target.Hp) {
+			target = &units[i]
+		}
+	}
+
+	return target
+}
@@ -0,0 +1,158 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/cdrpl/idlemon-server"
+)
+
+// Returns a battle unit of a type without advantages so every attack hits for a predictable amount.
+func CreateTestBattleUnit(hp int, atk int, def int, skills ...Skill) BattleUnit {
+	return BattleUnit{
+		Type:      UNIT_TYPE_SHADOW,
+		Hp:        hp,
+		MaxHp:     hp,
+		Atk:       atk,
+		Def:       def,
+		Skills:    skills,
+		Cooldowns: make([]int, len(skills)),
+	}
+}
+
+func TestSkillTargets(t *testing.T) {
+	adv := idlemonServer.DataCache.TypeAdvantage
+	rng := CreateSeededRng(1)
+
+	tests := []struct {
+		name    string
+		skill   Skill
+		allies  []int // Expected ally hp after the skill, the user is the first ally.
+		enemies []int // Expected enemy hp after the skill.
+	}{
+		{"lowest hp enemy", Skill{Target: SKILL_TARGET_ENEMY, Damage: 100}, []int{100, 40, 90}, []int{50, 10, 0}},
+		{"all enemies", Skill{Target: SKILL_TARGET_ALL_ENEMIES, Damage: 50}, []int{100, 40, 90}, []int{40, 20, 0}},
+		{"self", Skill{Target: SKILL_TARGET_SELF, Heal: 100}, []int{100, 40, 90}, []int{50, 30, 0}},
+		{"lowest hp ally", Skill{Target: SKILL_TARGET_ALLY, Heal: 50}, []int{100, 50, 90}, []int{50, 30, 0}},
+		{"all allies", Skill{Target: SKILL_TARGET_ALL_ALLIES, Heal: 50}, []int{100, 50, 100}, []int{50, 30, 0}},
+	}
+
+	for _, test := range tests {
+		allies := []BattleUnit{CreateTestBattleUnit(100, 20, 0), CreateTestBattleUnit(100, 20, 0), CreateTestBattleUnit(100, 20, 0)}
+		allies[1].Hp = 40
+		allies[2].Hp = 90
+
+		enemies := []BattleUnit{CreateTestBattleUnit(100, 20, 0), CreateTestBattleUnit(100, 20, 0), CreateTestBattleUnit(100, 20, 0)}
+		enemies[0].Hp = 50
+		enemies[1].Hp = 30
+		enemies[2].Hp = 0 // defeated units are never targeted
+
+		allies[0].UseSkill(adv, rng, test.skill, allies, enemies)
+
+		for i := range allies {
+			if allies[i].Hp != test.allies[i] {
+				t.Errorf("%v: expect ally %v hp %v, receive: %v", test.name, i, test.allies[i], allies[i].Hp)
+			}
+		}
+
+		for i := range enemies {
+			if enemies[i].Hp != test.enemies[i] {
+				t.Errorf("%v: expect enemy %v hp %v, receive: %v", test.name, i, test.enemies[i], enemies[i].Hp)
+			}
+		}
+	}
+}
+
+func TestSkillRounds(t *testing.T) {
+	adv := idlemonServer.DataCache.TypeAdvantage
+	rng := CreateSeededRng(2)
+
+	strike := Skill{Type: SKILL_ACTIVE, Target: SKILL_TARGET_ENEMY, Damage: 300, Cooldown: 2}
+	rally := Skill{Type: SKILL_ACTIVE, Target: SKILL_TARGET_SELF, Cooldown: 3, Effects: []SkillEffect{{Stat: SKILL_STAT_ATK, Amount: 50, Duration: 2}}}
+
+	allies := []BattleUnit{CreateTestBattleUnit(100, 20, 0, rally, strike)}
+	enemies := []BattleUnit{CreateTestBattleUnit(1000, 0, 0)}
+	user := &allies[0]
+
+	// the state after every round, the unit uses its first ready skill or a basic attack each round
+	rounds := []struct {
+		enemyHp   int
+		cooldowns []int
+		atk       int
+	}{
+		{1000, []int{2, 0}, 30}, // rally, the effect has 1 round left
+		{910, []int{1, 1}, 20},  // strike with the buffed atk, the effect runs out
+		{890, []int{0, 0}, 20},  // basic attack
+		{890, []int{2, 0}, 30},  // rally again
+		{800, []int{1, 1}, 20},  // strike
+	}
+
+	for round, expect := range rounds {
+		if i := user.ReadySkill(); i != -1 {
+			user.UseSkill(adv, rng, user.Skills[i], allies, enemies)
+			user.Cooldowns[i] = user.Skills[i].Cooldown
+		} else {
+			user.Attack(adv, rng, LowestHpTarget(enemies))
+		}
+
+		user.EndRound()
+
+		if enemies[0].Hp != expect.enemyHp {
+			t.Errorf("round %v: expect enemy hp %v, receive: %v", round+1, expect.enemyHp, enemies[0].Hp)
+		}
+
+		for i, cooldown := range expect.cooldowns {
+			if user.Cooldowns[i] != cooldown {
+				t.Errorf("round %v: expect skill %v cooldown %v, receive: %v", round+1, i, cooldown, user.Cooldowns[i])
+			}
+		}
+
+		if atk := user.Stat(SKILL_STAT_ATK); atk != expect.atk {
+			t.Errorf("round %v: expect atk %v, receive: %v", round+1, expect.atk, atk)
+		}
+	}
+}
+
+func TestPassiveSkillEffects(t *testing.T) {
+	adv := idlemonServer.DataCache.TypeAdvantage
+	rng := CreateSeededRng(3)
+
+	guard := Skill{Type: SKILL_PASSIVE, Target: SKILL_TARGET_ALL_ALLIES, Effects: []SkillEffect{{Stat: SKILL_STAT_DEF, Amount: 50, Duration: 1}}}
+
+	allies := []BattleUnit{CreateTestBattleUnit(100, 20, 10, guard), CreateTestBattleUnit(100, 20, 20)}
+	allies[0].UseSkill(adv, rng, guard, allies, nil)
+
+	// passive effects ignore their duration and last the whole battle
+	for round := 1; round <= BATTLE_MAX_ROUNDS; round++ {
+		for i := range allies {
+			allies[i].EndRound()
+		}
+
+		if def := allies[0].Stat(SKILL_STAT_DEF); def != 15 {
+			t.Fatalf("round %v: expect def 15, receive: %v", round, def)
+		}
+
+		if def := allies[1].Stat(SKILL_STAT_DEF); def != 30 {
+			t.Fatalf("round %v: expect def 30, receive: %v", round, def)
+		}
+	}
+}
+
+func TestSimulateBattleSeeded(t *testing.T) {
+	dc := idlemonServer.DataCache
+
+	team := func() []BattleUnit {
+		units := make([]BattleUnit, 0)
+		for _, template := range dc.UnitTemplates {
+			units = append(units, CreateBattleUnit(dc, CreateUnit(template.ID)))
+		}
+		return units
+	}
+
+	// the same seed should play out the same battle
+	a := SimulateBattle(dc.TypeAdvantage, CreateSeededRng(4), team(), team())
+	b := SimulateBattle(dc.TypeAdvantage, CreateSeededRng(4), team(), team())
+
+	if a != b {
+		t.Fatalf("expect battles with the same seed to match, receive: %v and %v", a, b)
+	}
+}
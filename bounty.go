@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// A timed mission that units can be dispatched on. Units on a bounty are busy until the reward is claimed.
+type Bounty struct {
+	Id        int         `json:"id"`
+	UserId    uuid.UUID   `json:"-"`
+	BoardDate time.Time   `json:"boardDate"`
+	Duration  int         `json:"duration"`  // Seconds until the reward can be claimed.
+	UnitType  int         `json:"unitType"`  // Required unit type or BOUNTY_ANY_UNIT_TYPE.
+	MinStars  int         `json:"minStars"`  // Every unit must have at least this many stars.
+	UnitCount int         `json:"unitCount"` // Number of units that must be dispatched.
+	Reward    Transaction `json:"reward"`
+	StartedAt *time.Time  `json:"startedAt"`
+	IsClaimed bool        `json:"isClaimed"`
+}
+
+// A weighted template used to generate the bounties on the board.
+type BountyTemplate struct {
+	Weight    int         `json:"weight"`
+	Duration  int         `json:"duration"`
+	UnitType  int         `json:"unitType"`
+	MinStars  int         `json:"minStars"`
+	UnitCount int         `json:"unitCount"`
+	Reward    Transaction `json:"reward"`
+}
+
+func UnMarshalBountiesJson() ([]BountyTemplate, error) {
+	var data map[string][]BountyTemplate
+
+	err := json.Unmarshal([]byte(bountiesJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["bounties"], nil
+}
+
+// Returns the date of today's bounty board.
+func BountyBoardDate() time.Time {
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Will generate a random bounty for today's board.
+func GenerateBounty(dc *DataCache, rng Rng, userId uuid.UUID) Bounty {
+	weights := make([]int, len(dc.Bounties))
+	for i, template := range dc.Bounties {
+		weights[i] = template.Weight
+	}
+
+	template := dc.Bounties[rng.Weighted(weights)]
+
+	return Bounty{
+		UserId:    userId,
+		BoardDate: BountyBoardDate(),
+		Duration:  template.Duration,
+		UnitType:  template.UnitType,
+		MinStars:  template.MinStars,
+		UnitCount: template.UnitCount,
+		Reward:    template.Reward,
+	}
+}
+
+// Returns true if the bounty has been started and the duration has passed.
+func (b *Bounty) IsComplete() bool {
+	return b.StartedAt != nil && time.Since(*b.StartedAt) >= time.Duration(b.Duration)*time.Second
+}
+
+// Returns an error describing why the unit cannot be dispatched on the bounty.
+func (b *Bounty) CheckUnit(dc *DataCache, unit Unit) error {
+	if unit.IsBusy {
+		return fmt.Errorf("unit %v is busy", unit.Id)
+	}
+
+	if unit.Stars < b.MinStars {
+		return fmt.Errorf("unit %v must have at least %v stars", unit.Id, b.MinStars)
+	}
+
+	template, _ := FindUnitTemplate(dc, unit.Template)
+
+	if b.UnitType != BOUNTY_ANY_UNIT_TYPE && template.TypeID != b.UnitType {
+		return fmt.Errorf("unit %v is not the required type", unit.Id)
+	}
+
+	return nil
+}
+
+// Will start the bounty and mark the units as busy.
+func (b *Bounty) Dispatch(ctx context.Context, tx pgx.Tx, unitIds []uuid.UUID) error {
+	now := time.Now()
+
+	_, err := tx.Exec(ctx, "UPDATE bounties SET started_at = $1 WHERE id = $2", now, b.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update bounties row: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE units SET bounty_id = $1 WHERE id = ANY($2)", b.Id, unitIds)
+	if err != nil {
+		return fmt.Errorf("fail to update units rows: %w", err)
+	}
+
+	b.StartedAt = &now
+
+	return nil
+}
+
+// Will mark the bounty as claimed and free the dispatched units. The reward is not applied.
+func (b *Bounty) Claim(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, "UPDATE bounties SET is_claimed = TRUE WHERE id = $1", b.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update bounties row: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE units SET bounty_id = NULL WHERE bounty_id = $1", b.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update units rows: %w", err)
+	}
+
+	b.IsClaimed = true
+
+	return nil
+}
+
+// Will insert the bounty and set its ID.
+func InsertBounty(ctx context.Context, tx pgx.Tx, bounty *Bounty) error {
+	query := `INSERT INTO bounties (user_id, board_date, duration, unit_type, min_stars, unit_count, reward_type, reward_amount,
+			  reward_item_template) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+
+	err := tx.QueryRow(ctx, query, bounty.UserId, bounty.BoardDate, bounty.Duration, bounty.UnitType, bounty.MinStars,
+		bounty.UnitCount, bounty.Reward.Type, bounty.Reward.Amount, bounty.Reward.ItemTemplate).Scan(&bounty.Id)
+	if err != nil {
+		return fmt.Errorf("fail to insert bounties row: %w", err)
+	}
+
+	return nil
+}
+
+// Will find the bounties on the user's board. This includes today's bounties and any unclaimed bounties in progress.
+func FindBounties(ctx context.Context, tx pgx.Tx, userId uuid.UUID) ([]Bounty, error) {
+	bounties := make([]Bounty, 0)
+
+	query := `SELECT id, board_date, duration, unit_type, min_stars, unit_count, reward_type, reward_amount, reward_item_template,
+			  started_at, is_claimed
+			  FROM bounties WHERE user_id = $1 AND (board_date = $2 OR (started_at IS NOT NULL AND NOT is_claimed))
+			  ORDER BY id`
+
+	rows, err := tx.Query(ctx, query, userId, BountyBoardDate())
+	if err != nil {
+		return bounties, fmt.Errorf("fail to query bounties table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		bounty := Bounty{UserId: userId}
+
+		err := rows.Scan(&bounty.Id, &bounty.BoardDate, &bounty.Duration, &bounty.UnitType, &bounty.MinStars, &bounty.UnitCount,
+			&bounty.Reward.Type, &bounty.Reward.Amount, &bounty.Reward.ItemTemplate, &bounty.StartedAt, &bounty.IsClaimed)
+		if err != nil {
+			return bounties, fmt.Errorf("fail to scan bounties row: %w", err)
+		}
+
+		bounties = append(bounties, bounty)
+	}
+
+	return bounties, rows.Err()
+}
+
+// Will find a bounty owned by the user for update.
+func FindBountyLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, bountyId int) (Bounty, error) {
+	bounty := Bounty{Id: bountyId, UserId: userId}
+
+	query := `SELECT board_date, duration, unit_type, min_stars, unit_count, reward_type, reward_amount, reward_item_template,
+			  started_at, is_claimed
+			  FROM bounties WHERE (id = $1 AND user_id = $2) FOR UPDATE`
+
+	err := tx.QueryRow(ctx, query, bountyId, userId).Scan(&bounty.BoardDate, &bounty.Duration, &bounty.UnitType, &bounty.MinStars,
+		&bounty.UnitCount, &bounty.Reward.Type, &bounty.Reward.Amount, &bounty.Reward.ItemTemplate, &bounty.StartedAt, &bounty.IsClaimed)
+	if err != nil {
+		return bounty, fmt.Errorf("fail to query bounties row: %w", err)
+	}
+
+	return bounty, nil
+}
+
+// Will return the user's bounty board. A new board is generated if the user has no bounties for today.
+// Bounties from previous boards that were never started or already claimed are deleted.
+func FindOrCreateBountyBoard(ctx context.Context, tx pgx.Tx, dc *DataCache, rng Rng, userId uuid.UUID) ([]Bounty, error) {
+	var count int
+
+	query := "SELECT COUNT(*) FROM bounties WHERE (user_id = $1 AND board_date = $2)"
+	if err := tx.QueryRow(ctx, query, userId, BountyBoardDate()).Scan(&count); err != nil {
+		return nil, fmt.Errorf("fail to count bounties: %w", err)
+	}
+
+	if count == 0 {
+		query := "DELETE FROM bounties WHERE (user_id = $1 AND board_date < $2 AND (started_at IS NULL OR is_claimed))"
+		if _, err := tx.Exec(ctx, query, userId, BountyBoardDate()); err != nil {
+			return nil, fmt.Errorf("fail to delete old bounties: %w", err)
+		}
+
+		for i := 0; i < BOUNTY_BOARD_SIZE; i++ {
+			bounty := GenerateBounty(dc, rng, userId)
+
+			if err := InsertBounty(ctx, tx, &bounty); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return FindBounties(ctx, tx, userId)
+}
+
+// Will replace every bounty on today's board that hasn't been started with a newly generated bounty.
+func RefreshBountyBoard(ctx context.Context, tx pgx.Tx, dc *DataCache, rng Rng, userId uuid.UUID) error {
+	query := "DELETE FROM bounties WHERE (user_id = $1 AND board_date = $2 AND started_at IS NULL)"
+	cmdTag, err := tx.Exec(ctx, query, userId, BountyBoardDate())
+	if err != nil {
+		return fmt.Errorf("fail to delete bounties: %w", err)
+	}
+
+	for i := int64(0); i < cmdTag.RowsAffected(); i++ {
+		bounty := GenerateBounty(dc, rng, userId)
+
+		if err := InsertBounty(ctx, tx, &bounty); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
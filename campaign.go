@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -30,22 +31,25 @@ func (c *Campaign) UpdateLastCollectedAt(ctx context.Context, tx pgx.Tx) error {
 }
 
 // Will update the database to reflect collection of campaign resources. The transactions carried out are returned.
-func (c *Campaign) Collect(ctx context.Context, tx pgx.Tx) ([3]Transaction, error) {
+// The first three transactions are always user exp, gold, and exp stones, followed by any item drops.
+// The rates of the first three transactions and the max collect time are set by the campaign modifiers.
+func (c *Campaign) Collect(ctx context.Context, tx pgx.Tx, dc *DataCache, rng Rng, modifiers CampaignModifiers) ([]Transaction, error) {
 	timeDiff := time.Since(c.LastCollectedAt)
 
-	var transactions [3]Transaction
-	transactions[0] = Transaction{Type: TRANSACTION_USER_EXP}
-	transactions[1] = Transaction{Type: TRANSACTION_GOLD}
-	transactions[2] = Transaction{Type: TRANSACTION_EXP_STONES}
+	transactions := []Transaction{
+		{Type: TRANSACTION_USER_EXP},
+		{Type: TRANSACTION_GOLD},
+		{Type: TRANSACTION_EXP_STONES},
+	}
 
 	// min time interval between collections
 	if timeDiff < time.Second {
 		return transactions, nil
 	}
 
-	// limit stockpile to 24 hours
-	if timeDiff > CAMPAIGN_MAX_COLLECT {
-		timeDiff = CAMPAIGN_MAX_COLLECT
+	// limit stockpile to the max collect time
+	if timeDiff > modifiers.MaxCollect {
+		timeDiff = modifiers.MaxCollect
 	}
 
 	// calculate rewards
@@ -54,6 +58,11 @@ func (c *Campaign) Collect(ctx context.Context, tx pgx.Tx) ([3]Transaction, erro
 	gold := timeDiffSec * (CAMPAIGN_GOLD_PER_SEC + (c.Level / 5 * CAMPAIGN_GOLD_GROWTH))
 	expStones := timeDiffSec * (CAMPAIGN_EXP_STONE_PER_SEC + (c.Level / 5 * CAMPAIGN_EXP_STONE_GROWTH))
 
+	// apply rate modifiers
+	exp = exp * modifiers.ExpRate / 100
+	gold = gold * modifiers.GoldRate / 100
+	expStones = expStones * modifiers.ExpStoneRate / 100
+
 	c.LastCollectedAt = time.Now()
 
 	if err := c.UpdateLastCollectedAt(ctx, tx); err != nil {
@@ -72,15 +81,28 @@ func (c *Campaign) Collect(ctx context.Context, tx pgx.Tx) ([3]Transaction, erro
 		return transactions, fmt.Errorf("fail to increase exp stone resource: %w", err)
 	}
 
+	if err := IncPrestigeLifetimeStats(ctx, tx, c.UserId, exp, gold, expStones); err != nil {
+		return transactions, fmt.Errorf("fail to increase lifetime stats: %w", err)
+	}
+
 	transactions[0].Amount = exp
 	transactions[1].Amount = gold
 	transactions[2].Amount = expStones
 
-	return transactions, nil
+	// roll the drop table once for every elapsed interval
+	drops := RollCampaignDrops(dc, rng, c.Level, timeDiffSec)
+
+	for _, drop := range drops {
+		if err := drop.Apply(ctx, tx, c.UserId); err != nil {
+			return transactions, fmt.Errorf("fail to apply campaign drop: %w", err)
+		}
+	}
+
+	return append(transactions, drops...), nil
 }
 
 func FindCampaign(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) (Campaign, error) {
-	var campaign Campaign
+	campaign := Campaign{UserId: userId}
 
 	query := "SELECT id, level, last_collected_at FROM campaign WHERE user_id = $1"
 	err := db.QueryRow(ctx, query, userId).Scan(&campaign.Id, &campaign.Level, &campaign.LastCollectedAt)
@@ -91,6 +113,37 @@ func FindCampaign(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) (Camp
 	return campaign, nil
 }
 
+// Will find the user's campaign row for update.
+func FindCampaignLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID) (Campaign, error) {
+	campaign := Campaign{UserId: userId}
+
+	query := "SELECT id, level, last_collected_at FROM campaign WHERE user_id = $1 FOR UPDATE"
+	err := tx.QueryRow(ctx, query, userId).Scan(&campaign.Id, &campaign.Level, &campaign.LastCollectedAt)
+	if err != nil {
+		return campaign, fmt.Errorf("fail to query campaign row: %w", err)
+	}
+
+	return campaign, nil
+}
+
+// Will advance the campaign to the next stage.
+func (c *Campaign) IncreaseLevel(ctx context.Context, tx pgx.Tx) error {
+	query := "UPDATE campaign SET level = level + 1 WHERE id = $1"
+
+	_, err := tx.Exec(ctx, query, c.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update campaign row: %w", err)
+	}
+
+	c.Level++
+
+	if err := UpdateHighestCampaignLevel(ctx, tx, c.UserId, c.Level); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func InsertCampaign(ctx context.Context, tx pgx.Tx, userId uuid.UUID) error {
 	now := time.Now()
 
@@ -102,3 +155,69 @@ func InsertCampaign(ctx context.Context, tx pgx.Tx, userId uuid.UUID) error {
 
 	return nil
 }
+
+// A weighted list of drops that is rolled once every interval while the campaign is idle.
+type CampaignDropTable struct {
+	MinLevel int            `json:"minLevel"` // The table applies to campaign levels at or above this value.
+	Interval int            `json:"interval"` // Seconds of idle time required for each roll.
+	Drops    []CampaignDrop `json:"drops"`
+}
+
+// A drop with a zero amount transaction represents an empty roll.
+type CampaignDrop struct {
+	Weight      int         `json:"weight"`
+	Transaction Transaction `json:"transaction"`
+}
+
+func UnMarshalCampaignDropsJson() ([]CampaignDropTable, error) {
+	var data map[string][]CampaignDropTable
+
+	err := json.Unmarshal([]byte(campaignDropsJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["campaignDrops"], nil
+}
+
+// Returns the drop table with the highest min level that the campaign level has reached.
+func FindCampaignDropTable(dc *DataCache, level int) (CampaignDropTable, bool) {
+	var table CampaignDropTable
+	found := false
+
+	for _, dropTable := range dc.CampaignDrops {
+		if level >= dropTable.MinLevel && (!found || dropTable.MinLevel > table.MinLevel) {
+			table = dropTable
+			found = true
+		}
+	}
+
+	return table, found
+}
+
+// Will roll the campaign drop table for the given idle time. Drops of the same type are merged together.
+func RollCampaignDrops(dc *DataCache, rng Rng, level int, seconds int) []Transaction {
+	drops := make([]Transaction, 0)
+
+	table, ok := FindCampaignDropTable(dc, level)
+	if !ok || table.Interval <= 0 {
+		return drops
+	}
+
+	weights := make([]int, len(table.Drops))
+	for i, drop := range table.Drops {
+		weights[i] = drop.Weight
+	}
+
+	rolls := seconds / table.Interval
+
+	for i := 0; i < rolls; i++ {
+		drop := table.Drops[rng.Weighted(weights)]
+
+		if drop.Transaction.Amount != 0 {
+			drops = append(drops, drop.Transaction)
+		}
+	}
+
+	return MergeTransactions(drops)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// A chapter chest can be claimed once the total stars earned in the chapter reaches the required stars.
+type CampaignChest struct {
+	Stars       int         `json:"stars"`
+	Transaction Transaction `json:"transaction"`
+	IsClaimed   bool        `json:"isClaimed"`
+}
+
+// Summary of the user's progress in a campaign chapter.
+type CampaignChapter struct {
+	Chapter       int             `json:"chapter"`
+	StagesCleared int             `json:"stagesCleared"`
+	Stars         int             `json:"stars"`
+	MaxStars      int             `json:"maxStars"`
+	Chests        []CampaignChest `json:"chests"`
+}
+
+// Game data describing the enemy levels of a campaign chapter. Enemy levels rise evenly from the first to the last
+// stage of the chapter. Chapters after the last entry continue the curve of the last entry.
+type CampaignChapterConfig struct {
+	Chapter         int `json:"chapter"`
+	FirstEnemyLevel int `json:"firstEnemyLevel"` // The enemy level on the first stage of the chapter.
+	LastEnemyLevel  int `json:"lastEnemyLevel"`  // The enemy level on the last stage of the chapter.
+}
+
+func UnMarshalCampaignChaptersJson() ([]CampaignChapterConfig, error) {
+	var data map[string][]CampaignChapterConfig
+
+	err := json.Unmarshal([]byte(campaignChaptersJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["campaignChapters"], nil
+}
+
+// Returns the config with the highest chapter that the given chapter has reached.
+func FindCampaignChapterConfig(dc *DataCache, chapter int) (CampaignChapterConfig, bool) {
+	var config CampaignChapterConfig
+	found := false
+
+	for _, chapterConfig := range dc.CampaignChapters {
+		if chapter >= chapterConfig.Chapter && (!found || chapterConfig.Chapter > config.Chapter) {
+			config = chapterConfig
+			found = true
+		}
+	}
+
+	return config, found
+}
+
+// Returns the level of the enemies on a campaign stage. The level is never below 1.
+func CampaignStageEnemyLevel(dc *DataCache, stage int) int {
+	config, ok := FindCampaignChapterConfig(dc, CampaignStageChapter(stage))
+	if !ok {
+		return 1
+	}
+
+	first := (config.Chapter-1)*CAMPAIGN_CHAPTER_STAGES + 1
+	level := config.FirstEnemyLevel + (config.LastEnemyLevel-config.FirstEnemyLevel)*(stage-first)/(CAMPAIGN_CHAPTER_STAGES-1)
+
+	if level < 1 {
+		level = 1
+	}
+
+	return level
+}
+
+// Returns the chapter that the campaign stage belongs to.
+func CampaignStageChapter(stage int) int {
+	return (stage-1)/CAMPAIGN_CHAPTER_STAGES + 1
+}
+
+// Returns the chests of a chapter. Rewards increase with the chapter number.
+func CampaignChapterChests(chapter int) []CampaignChest {
+	maxStars := CAMPAIGN_CHAPTER_STAGES * CAMPAIGN_MAX_STARS
+
+	return []CampaignChest{
+		{
+			Stars:       maxStars / 3,
+			Transaction: Transaction{Type: TRANSACTION_GEMS, Amount: 50 * chapter},
+		},
+		{
+			Stars:       maxStars * 2 / 3,
+			Transaction: Transaction{Type: TRANSACTION_EVO_STONES, Amount: 5 * chapter},
+		},
+		{
+			Stars:       maxStars,
+			Transaction: Transaction{Type: TRANSACTION_GEMS, Amount: 150 * chapter},
+		},
+	}
+}
+
+// Returns the enemy team of a campaign stage. The team size grows with the chapter and the enemy levels come from
+// the chapter config.
+func CampaignStageEnemies(dc *DataCache, stage int) []BattleUnit {
+	count := 2 + (stage-1)/CAMPAIGN_CHAPTER_STAGES
+	if count > BATTLE_MAX_TEAM_SIZE {
+		count = BATTLE_MAX_TEAM_SIZE
+	}
+
+	enemies := make([]BattleUnit, count)
+	level := CampaignStageEnemyLevel(dc, stage)
+
+	for i := range enemies {
+		template := dc.UnitTemplates[(stage*7+i*3)%len(dc.UnitTemplates)]
+
+		enemy := CreateUnit(template.ID)
+		enemy.Level = level
+
+		enemies[i] = CreateBattleUnit(dc, enemy)
+	}
+
+	return enemies
+}
+
+// Returns the star rating earned from a battle result. A lost battle earns 0 stars.
+func CampaignStars(result BattleResult) int {
+	switch {
+	case !result.Win:
+		return 0
+
+	case result.Survivors == result.TeamSize && result.Rounds <= CAMPAIGN_THREE_STAR_ROUNDS:
+		return 3
+
+	case result.Survivors*2 >= result.TeamSize:
+		return 2
+
+	default:
+		return 1
+	}
+}
+
+// Will save the stars earned on a stage. The stars will only be updated if they are higher than the previous rating.
+func UpsertCampaignStageStars(ctx context.Context, tx pgx.Tx, userId uuid.UUID, stage int, stars int) error {
+	query := `INSERT INTO campaign_stages (user_id, stage, stars) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id, stage) DO UPDATE SET stars = GREATEST(campaign_stages.stars, EXCLUDED.stars)`
+
+	_, err := tx.Exec(ctx, query, userId, stage, stars)
+	if err != nil {
+		return fmt.Errorf("fail to upsert campaign_stages row: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the total stars earned in the chapter.
+func FindCampaignChapterStars(ctx context.Context, tx pgx.Tx, userId uuid.UUID, chapter int) (int, error) {
+	var stars int
+
+	first := (chapter-1)*CAMPAIGN_CHAPTER_STAGES + 1
+	last := chapter * CAMPAIGN_CHAPTER_STAGES
+
+	query := "SELECT COALESCE(SUM(stars), 0) FROM campaign_stages WHERE (user_id = $1 AND stage >= $2 AND stage <= $3)"
+	err := tx.QueryRow(ctx, query, userId, first, last).Scan(&stars)
+	if err != nil {
+		return stars, fmt.Errorf("fail to query campaign_stages table: %w", err)
+	}
+
+	return stars, nil
+}
+
+// Will mark the chapter chest as claimed. Returns false if the chest was already claimed.
+func ClaimCampaignChest(ctx context.Context, tx pgx.Tx, userId uuid.UUID, chapter int, chest int) (bool, error) {
+	var id int
+
+	query := `INSERT INTO campaign_chests (user_id, chapter, chest, claimed_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (user_id, chapter, chest) DO NOTHING RETURNING id`
+
+	err := tx.QueryRow(ctx, query, userId, chapter, chest, time.Now()).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("fail to insert campaign_chests row: %w", err)
+	}
+
+	return true, nil
+}
+
+// Returns a summary of every chapter the campaign has reached.
+func FindCampaignChapters(ctx context.Context, db *pgxpool.Pool, campaign Campaign) ([]CampaignChapter, error) {
+	chapters := make([]CampaignChapter, CampaignStageChapter(campaign.Level))
+
+	for i := range chapters {
+		chapters[i] = CampaignChapter{
+			Chapter:  i + 1,
+			MaxStars: CAMPAIGN_CHAPTER_STAGES * CAMPAIGN_MAX_STARS,
+			Chests:   CampaignChapterChests(i + 1),
+		}
+	}
+
+	query := "SELECT stage, stars FROM campaign_stages WHERE user_id = $1"
+	rows, err := db.Query(ctx, query, campaign.UserId)
+	if err != nil {
+		return chapters, fmt.Errorf("fail to query campaign_stages table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stage, stars int
+
+		if err := rows.Scan(&stage, &stars); err != nil {
+			return chapters, fmt.Errorf("fail to scan campaign_stages row: %w", err)
+		}
+
+		if i := CampaignStageChapter(stage) - 1; i < len(chapters) {
+			chapters[i].StagesCleared++
+			chapters[i].Stars += stars
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return chapters, err
+	}
+
+	query = "SELECT chapter, chest FROM campaign_chests WHERE user_id = $1"
+	chestRows, err := db.Query(ctx, query, campaign.UserId)
+	if err != nil {
+		return chapters, fmt.Errorf("fail to query campaign_chests table: %w", err)
+	}
+	defer chestRows.Close()
+
+	for chestRows.Next() {
+		var chapter, chest int
+
+		if err := chestRows.Scan(&chapter, &chest); err != nil {
+			return chapters, fmt.Errorf("fail to scan campaign_chests row: %w", err)
+		}
+
+		if i := chapter - 1; i < len(chapters) && chest < len(chapters[i].Chests) {
+			chapters[i].Chests[chest].IsClaimed = true
+		}
+	}
+
+	return chapters, chestRows.Err()
+}
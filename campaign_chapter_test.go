@@ -0,0 +1,54 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/cdrpl/idlemon-server"
+)
+
+func TestCampaignStageEnemyLevel(t *testing.T) {
+	dc := idlemonServer.DataCache
+
+	for _, config := range dc.CampaignChapters {
+		first := (config.Chapter-1)*CAMPAIGN_CHAPTER_STAGES + 1
+		last := config.Chapter * CAMPAIGN_CHAPTER_STAGES
+
+		if level := CampaignStageEnemyLevel(dc, first); level != config.FirstEnemyLevel {
+			t.Errorf("chapter %v: expect first stage level %v, receive: %v", config.Chapter, config.FirstEnemyLevel, level)
+		}
+
+		if level := CampaignStageEnemyLevel(dc, last); level != config.LastEnemyLevel {
+			t.Errorf("chapter %v: expect last stage level %v, receive: %v", config.Chapter, config.LastEnemyLevel, level)
+		}
+	}
+
+	// stages after the last configured chapter keep getting harder
+	last := len(dc.CampaignChapters) * CAMPAIGN_CHAPTER_STAGES
+	if CampaignStageEnemyLevel(dc, last+CAMPAIGN_CHAPTER_STAGES) <= CampaignStageEnemyLevel(dc, last) {
+		t.Errorf("expect enemy levels to keep rising after the last configured chapter")
+	}
+}
+
+// A team of templates 1 to 5 at the level cap of 1 star units should clear the last stage of every configured chapter.
+func TestCampaignChapterClear(t *testing.T) {
+	dc := idlemonServer.DataCache
+	levelCap := dc.UnitProgression.LevelCap(1)
+
+	for _, config := range dc.CampaignChapters {
+		stage := config.Chapter * CAMPAIGN_CHAPTER_STAGES
+
+		allies := make([]BattleUnit, 0, BATTLE_MAX_TEAM_SIZE)
+		for template := 1; template <= BATTLE_MAX_TEAM_SIZE; template++ {
+			unit := CreateUnit(template)
+			unit.Level = levelCap
+
+			allies = append(allies, CreateBattleUnit(dc, unit))
+		}
+
+		result := SimulateBattle(dc.TypeAdvantage, CreateSeededRng(int64(stage)), allies, CampaignStageEnemies(dc, stage))
+
+		if !result.Win {
+			t.Errorf("expect a level %v team to clear stage %v, receive: %+v", levelCap, stage, result)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/cdrpl/idlemon-server"
+)
+
+func TestRollCampaignDrops(t *testing.T) {
+	gold := Transaction{Type: TRANSACTION_GOLD, Amount: 1}
+	evoStones := Transaction{Type: TRANSACTION_EVO_STONES, Amount: 1}
+
+	// tables with a single drop give it on every roll
+	dc := &DataCache{CampaignDrops: []CampaignDropTable{
+		{MinLevel: 10, Interval: 50, Drops: []CampaignDrop{{Weight: 1, Transaction: evoStones}}},
+		{MinLevel: 1, Interval: 100, Drops: []CampaignDrop{{Weight: 1, Transaction: gold}}},
+	}}
+
+	tests := []struct {
+		name    string
+		level   int
+		seconds int
+		expect  []Transaction
+	}{
+		{"no table reached", 0, 1000, []Transaction{}},
+		{"less than an interval", 1, 99, []Transaction{}},
+		{"one roll per interval", 1, 350, []Transaction{{Type: TRANSACTION_GOLD, Amount: 3}}},
+		{"below the next table", 9, 350, []Transaction{{Type: TRANSACTION_GOLD, Amount: 3}}},
+		{"next table", 10, 350, []Transaction{{Type: TRANSACTION_EVO_STONES, Amount: 7}}},
+		{"highest table reached", 50, 100, []Transaction{{Type: TRANSACTION_EVO_STONES, Amount: 2}}},
+	}
+
+	for _, test := range tests {
+		drops := RollCampaignDrops(dc, CreateSeededRng(1), test.level, test.seconds)
+
+		if !reflect.DeepEqual(drops, test.expect) {
+			t.Errorf("%v: expect drops %+v, receive: %+v", test.name, test.expect, drops)
+		}
+	}
+
+	// empty drops are left out, every other roll is counted
+	dc.CampaignDrops = []CampaignDropTable{
+		{MinLevel: 1, Interval: 1, Drops: []CampaignDrop{{Weight: 1}, {Weight: 1, Transaction: gold}}},
+	}
+
+	drops := RollCampaignDrops(dc, CreateSeededRng(2), 1, 1000)
+	if len(drops) != 1 || drops[0].Type != TRANSACTION_GOLD || drops[0].Amount <= 0 || drops[0].Amount >= 1000 {
+		t.Fatalf("expect between 1 and 999 gold, receive: %+v", drops)
+	}
+
+	// the same seed should roll the same drops
+	again := RollCampaignDrops(dc, CreateSeededRng(2), 1, 1000)
+	if !reflect.DeepEqual(drops, again) {
+		t.Fatalf("expect drops with the same seed to match, receive: %+v and %+v", drops, again)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -51,11 +52,11 @@ func GetChatMessages(ctx context.Context, db *pgxpool.Pool, start int, num int)
 }
 
 // Insert chat message into the database and return the ID.
-func InsertChatMessage(ctx context.Context, db *pgxpool.Pool, senderId uuid.UUID, senderName string, message string) (int, error) {
+func InsertChatMessage(ctx context.Context, tx pgx.Tx, senderId uuid.UUID, senderName string, message string) (int, error) {
 	var id int
 
 	query := "INSERT INTO chat_messages (user_id, sender_name, message, sent_at) VALUES ($1, $2, $3, $4) RETURNING id"
-	err := db.QueryRow(ctx, query, senderId, senderName, message, time.Now()).Scan(&id)
+	err := tx.QueryRow(ctx, query, senderId, senderName, message, time.Now()).Scan(&id)
 
 	return id, err
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// A unit template in the user's codex. Templates are discovered the first time the user owns a unit of the template.
+type CodexEntry struct {
+	Template     int        `json:"template"`
+	IsDiscovered bool       `json:"isDiscovered"`
+	HighestStars int        `json:"highestStars"`
+	DiscoveredAt *time.Time `json:"discoveredAt"`
+}
+
+// Returns the one time reward for discovering a unit template.
+func CodexDiscoveryReward() Transaction {
+	return Transaction{Type: TRANSACTION_GEMS, Amount: CODEX_DISCOVERY_REWARD}
+}
+
+// Will record the unit in the user's codex. The highest stars are only updated if the unit has more stars.
+// Returns true if the unit's template was discovered for the first time.
+func UpdateCodex(ctx context.Context, tx pgx.Tx, userId uuid.UUID, unit Unit) (bool, error) {
+	var id int
+
+	query := `INSERT INTO codex (user_id, template, highest_stars, discovered_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (user_id, template) DO NOTHING RETURNING id`
+
+	err := tx.QueryRow(ctx, query, userId, unit.Template, unit.Stars, time.Now()).Scan(&id)
+	if err == nil {
+		return true, nil
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("fail to insert codex row: %w", err)
+	}
+
+	query = "UPDATE codex SET highest_stars = GREATEST(highest_stars, $1) WHERE (user_id = $2 AND template = $3)"
+	if _, err := tx.Exec(ctx, query, unit.Stars, userId, unit.Template); err != nil {
+		return false, fmt.Errorf("fail to update codex row: %w", err)
+	}
+
+	return false, nil
+}
+
+// Returns a codex entry for every unit template. Undiscovered templates are included.
+func FindCodex(ctx context.Context, db *pgxpool.Pool, dc *DataCache, userId uuid.UUID) ([]CodexEntry, error) {
+	entries := make([]CodexEntry, len(dc.UnitTemplates))
+	index := make(map[int]int)
+
+	for i, template := range dc.UnitTemplates {
+		entries[i] = CodexEntry{Template: template.ID}
+		index[template.ID] = i
+	}
+
+	query := "SELECT template, highest_stars, discovered_at FROM codex WHERE user_id = $1"
+	rows, err := db.Query(ctx, query, userId)
+	if err != nil {
+		return entries, fmt.Errorf("fail to query codex table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry CodexEntry
+
+		if err := rows.Scan(&entry.Template, &entry.HighestStars, &entry.DiscoveredAt); err != nil {
+			return entries, fmt.Errorf("fail to scan codex row: %w", err)
+		}
+
+		if i, ok := index[entry.Template]; ok {
+			entry.IsDiscovered = true
+			entries[i] = entry
+		}
+	}
+
+	return entries, rows.Err()
+}
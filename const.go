@@ -5,25 +5,53 @@ import (
 )
 
 const (
-	VERSION           = "0.0.1"        // The current version of the server.
-	ENV_FILE          = ".env"         // Default path to the .env file
-	MAX_REQ_BODY_SIZE = 512            // Maximum number of bytes allowed in a request body.
-	API_TOKEN_LEN     = 32             // Number of characters in the API token.
-	API_TOKEN_TTL     = time.Hour * 12 // Time until the API token expires.
-	MAX_PG_CONN       = 10             // Maximum number of open Postgres connections.
-	UNIT_SUMMON_COST  = 250            // The cost to summon a unit.
-	BCRYPT_COST       = 11             // The bcrypt cost used to hash a user's password.
-	CHAT_LOG_LEN      = 15             // The amount of chat messages returned when fetching chat history.
+	VERSION                = "0.0.1"        // The current version of the server.
+	ENV_FILE               = ".env"         // Default path to the .env file
+	MAX_REQ_BODY_SIZE      = 4096           // Maximum number of bytes allowed in a request body.
+	API_TOKEN_LEN          = 32             // Number of characters in the API token.
+	API_TOKEN_TTL          = time.Hour * 12 // Time until the API token expires.
+	MAX_PG_CONN            = 10             // Maximum number of open Postgres connections.
+	UNIT_SUMMON_COST       = 250            // The cost to summon a unit.
+	BCRYPT_COST            = 11             // The bcrypt cost used to hash a user's password.
+	CHAT_LOG_LEN           = 15             // The amount of chat messages returned when fetching chat history.
+	CODEX_DISCOVERY_REWARD = 50             // The gems rewarded the first time a unit template is discovered.
+	FREE_SUMMON_INTERVAL   = time.Hour * 24 // Time between a user's free summons.
+	SUMMON_STANDARD_BANNER = 1              // The banner used when a summon doesn't specify one.
 )
 
 const (
-	CAMPAIGN_MAX_COLLECT       = time.Hour * 24 // Max time before campaign cannot collect anymore
+	CAMPAIGN_MAX_COLLECT       = time.Hour * 24 // Max time before campaign cannot collect anymore, can be extended by idle upgrades
 	CAMPAIGN_EXP_PER_SEC       = 5              // The amount of exp earned every second on campaign level 1
 	CAMPAIGN_GOLD_PER_SEC      = 20             // The amount of gold earned every second on campaign level 1
 	CAMPAIGN_EXP_STONE_PER_SEC = 2              // The amount of exp stones earned every second on campaign level 1
 	CAMPAIGN_EXP_GROWTH        = 2              // Exp gained from campaign increase by this value every 5 levels
 	CAMPAIGN_GOLD_GROWTH       = 1              // Gold gained from campaign increase by this value every 5 levels
 	CAMPAIGN_EXP_STONE_GROWTH  = 3              // Exp stones gained from campaign increase by this value every 5 levels
+	CAMPAIGN_CHAPTER_STAGES    = 10             // The number of campaign stages in each chapter
+	CAMPAIGN_MAX_STARS         = 3              // The max star rating that can be earned on a campaign stage
+	CAMPAIGN_THREE_STAR_ROUNDS = 5              // A battle must be won within this many rounds to earn 3 stars
+	PRESTIGE_MIN_LEVEL         = 30             // The campaign level required to prestige
+	PRESTIGE_MULTIPLIER        = 25             // Campaign collect rates increase by this percent for every prestige
+)
+
+const (
+	BOUNTY_BOARD_SIZE    = 5  // The number of bounties generated on the daily bounty board.
+	BOUNTY_REFRESH_COST  = 50 // The gem cost to refresh the bounty board.
+	BOUNTY_MAX_UNITS     = 5  // The max number of units that can be dispatched on a bounty.
+	BOUNTY_ANY_UNIT_TYPE = -1 // Bounty unit type that accepts units of every type.
+)
+
+const (
+	BATTLE_MAX_ROUNDS        = 30 // The battle is lost if the enemies are not defeated within this many rounds.
+	BATTLE_MAX_TEAM_SIZE     = 5  // The max number of units that can be brought into a battle.
+	TEAM_MAX_COUNT           = 10 // The max number of saved teams a user can have.
+	UNIT_STAT_GROWTH         = 10 // Unit stats increase by this percent of the base stats every level.
+	UNIT_STAR_STAT_GROWTH    = 20 // Unit stats increase by this percent of the base stats every star.
+	UNIT_MAX_LEVEL_UPS       = 50 // The max number of levels a unit can gain in a single level up request.
+	UNIT_MAX_FODDER          = 10 // The max number of fodder units that can be sent in an evolve request.
+	UNIT_MAX_BULK            = 50 // The max number of units in a bulk lock or retire request.
+	SUMMON_HISTORY_PAGE_SIZE = 50 // The max number of records returned by the summon history route.
+	UNITS_PAGE_SIZE          = 50 // The max number of units returned by the unit list route.
 )
 
 // Request DTOs validation.
@@ -35,6 +63,8 @@ const (
 	USER_EMAIL_MAX       = 255
 	USER_PASS_MIN        = 8
 	USER_PASS_MAX        = 255
+	TEAM_NAME_MIN        = 1
+	TEAM_NAME_MAX        = 16
 )
 
 // Unit types, must have the same value as their table row IDs.
@@ -47,17 +77,54 @@ const (
 	UNIT_TYPE_DARK
 )
 
+// Unit rarities.
+const (
+	RARITY_COMMON = iota
+	RARITY_RARE
+	RARITY_EPIC
+	RARITY_LEGENDARY
+)
+
 // Resources, must have the same value as their table row IDs.
 const (
 	RESOURCE_GOLD = iota
 	RESOURCE_GEMS
 	RESOURCE_EXP_STONE
 	RESOURCE_EVO_STONE
+	RESOURCE_SUMMON_TICKET
 )
 
-// Daily quest IDs.
+// Idle upgrade types.
+const (
+	IDLE_UPGRADE_STORAGE = iota
+	IDLE_UPGRADE_EXP_RATE
+	IDLE_UPGRADE_GOLD_RATE
+	IDLE_UPGRADE_EXP_STONE_RATE
+)
+
+// Daily quest objective types.
 const (
 	DAILY_QUEST_SIGN_IN = iota
+	DAILY_QUEST_COLLECT_CAMPAIGN
+	DAILY_QUEST_SUMMON_UNITS
+	DAILY_QUEST_LEVEL_UP_UNITS
+	DAILY_QUEST_WIN_BATTLES
+	DAILY_QUEST_SEND_CHAT
+)
+
+// Achievement objective types.
+const (
+	ACHIEVEMENT_SUMMON_UNITS = iota
+	ACHIEVEMENT_LEVEL_UP_UNITS
+	ACHIEVEMENT_WIN_BATTLES
+	ACHIEVEMENT_COLLECT_CAMPAIGN
+	ACHIEVEMENT_SEND_CHAT
+)
+
+// Error codes sent with error responses, 0 means no specific error.
+const (
+	ERR_CODE_NONE = iota
+	ERR_CODE_ROSTER_FULL
 )
 
 // WebSocket message types.
@@ -71,6 +138,52 @@ const (
 	TRANSACTION_GOLD
 	TRANSACTION_EXP_STONES
 	TRANSACTION_USER_EXP
+	TRANSACTION_EVO_STONES
+	TRANSACTION_ITEM
+	TRANSACTION_SUMMON_TICKETS
+	TRANSACTION_FREE_SUMMON // Pays for a free summon, applying it changes nothing.
+)
+
+// Currencies that can pay for a single summon.
+const (
+	SUMMON_CURRENCY_GEMS    = "gems"
+	SUMMON_CURRENCY_TICKETS = "tickets"
+	SUMMON_CURRENCY_FREE    = "free" // Available once every FREE_SUMMON_INTERVAL.
+)
+
+// Unit list sort options.
+const (
+	UNIT_SORT_LEVEL = "level"
+	UNIT_SORT_STARS = "stars"
+	UNIT_SORT_POWER = "power"
+)
+
+// Skill types.
+const (
+	SKILL_ACTIVE = iota
+	SKILL_PASSIVE
+)
+
+// Skill targets.
+const (
+	SKILL_TARGET_ENEMY = iota // The enemy with the lowest hp.
+	SKILL_TARGET_ALL_ENEMIES
+	SKILL_TARGET_SELF
+	SKILL_TARGET_ALLY // The ally with the lowest hp.
+	SKILL_TARGET_ALL_ALLIES
+)
+
+// Stats that can be modified by skill effects.
+const (
+	SKILL_STAT_ATK = iota
+	SKILL_STAT_DEF
+)
+
+// Item slots, a unit can wear one item in each slot.
+const (
+	ITEM_SLOT_WEAPON = iota
+	ITEM_SLOT_ARMOR
+	ITEM_SLOT_ACCESSORY
 )
 
 const (
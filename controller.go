@@ -2,11 +2,13 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
@@ -18,12 +20,14 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-func CreateController(db *pgxpool.Pool, rdb *redis.Client, wsHub *WsHub, dataCache *DataCache) *Controller {
+func CreateController(db *pgxpool.Pool, rdb *redis.Client, wsHub *WsHub, dataCache *DataCache, rng Rng, bus *EventBus) *Controller {
 	return &Controller{
 		db:        db,
 		rdb:       rdb,
 		wsHub:     wsHub,
 		dataCache: dataCache,
+		rng:       rng,
+		bus:       bus,
 	}
 }
 
@@ -32,6 +36,8 @@ type Controller struct {
 	rdb       *redis.Client
 	wsHub     *WsHub
 	dataCache *DataCache
+	rng       Rng
+	bus       *EventBus
 }
 
 /* App Routes */
@@ -64,6 +70,302 @@ func (c Controller) NotFound(w http.ResponseWriter, r *http.Request) {
 	ErrRes(w, http.StatusNotFound)
 }
 
+/* Achievement Routes */
+
+// Will return the user's progress on every achievement.
+func (c Controller) AchievementList(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	progress, err := FindAchievementProgress(r.Context(), c.db, c.dataCache, userId)
+	if err != nil {
+		log.Printf("fail to find achievement progress: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, AchievementListRes{Progress: progress})
+}
+
+// Will give the achievement rewards once the user has reached the achievement. Rewards can only be claimed once.
+func (c Controller) AchievementClaim(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	achievementId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "achievement ID should be an integer")
+		return
+	}
+
+	achievement, ok := FindAchievement(c.dataCache, achievementId)
+	if !ok {
+		ErrRes(w, http.StatusNotFound)
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("achievement claim error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	claimed, err := ClaimAchievement(r.Context(), tx, userId, achievement)
+	if err != nil {
+		log.Printf("fail to claim achievement: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !claimed {
+		ErrResCustom(w, http.StatusBadRequest, "achievement is not reached or was already claimed")
+		return
+	}
+
+	for _, reward := range achievement.Rewards {
+		if err := reward.Apply(r.Context(), tx, userId); err != nil {
+			log.Printf("fail to apply achievement reward: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("achievement claim error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v claimed achievement %v\n", userId, achievementId)
+	JsonRes(w, AchievementClaimRes{Rewards: achievement.Rewards})
+}
+
+/* Bounty Routes */
+
+// Will return the user's bounty board. A new board is generated every day.
+func (c Controller) BountyBoard(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("bounty board error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	bounties, err := FindOrCreateBountyBoard(r.Context(), tx, c.dataCache, c.rng, userId)
+	if err != nil {
+		log.Printf("fail to find bounty board: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("bounty board error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, BountyBoardRes{Bounties: bounties})
+}
+
+// Will replace the bounties that haven't been started with new bounties. Costs gems.
+func (c Controller) BountyBoardRefresh(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("bounty board refresh error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	gems, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_GEMS)
+	if err != nil {
+		log.Printf("fail to find gems resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if gems.Amount < BOUNTY_REFRESH_COST {
+		ErrResCustom(w, http.StatusBadRequest, "not enough gems")
+		return
+	}
+
+	// make sure today's board exists before refreshing it
+	if _, err := FindOrCreateBountyBoard(r.Context(), tx, c.dataCache, c.rng, userId); err != nil {
+		log.Printf("fail to find bounty board: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := RefreshBountyBoard(r.Context(), tx, c.dataCache, c.rng, userId); err != nil {
+		log.Printf("fail to refresh bounty board: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	transaction := Transaction{Type: TRANSACTION_GEMS, Amount: -BOUNTY_REFRESH_COST}
+
+	if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to decrease gems resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	bounties, err := FindBounties(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find bounties: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("bounty board refresh error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v refreshed their bounty board\n", userId)
+	JsonRes(w, BountyBoardRefreshRes{Bounties: bounties, Transaction: transaction})
+}
+
+// Will dispatch units on a bounty. The units must meet the bounty's requirements and will be busy until it's claimed.
+func (c Controller) BountyDispatch(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	req := GetReqDto(r).(*BountyDispatchReq)
+
+	bountyId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "bounty ID should be an integer")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("bounty dispatch error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	bounty, err := FindBountyLock(r.Context(), tx, userId, bountyId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find bounty: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if bounty.StartedAt != nil {
+		ErrResCustom(w, http.StatusBadRequest, "bounty has already been started")
+		return
+	}
+
+	if len(req.UnitIds) != bounty.UnitCount {
+		ErrResCustom(w, http.StatusBadRequest, fmt.Sprintf("bounty requires %v units", bounty.UnitCount))
+		return
+	}
+
+	units, err := FindUnitsLock(r.Context(), tx, userId, req.UnitIds)
+	if err != nil {
+		log.Printf("fail to find units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if len(units) != len(req.UnitIds) {
+		ErrResCustom(w, http.StatusBadRequest, "unit not found")
+		return
+	}
+
+	for _, unit := range units {
+		if err := bounty.CheckUnit(c.dataCache, unit); err != nil {
+			ErrResCustom(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := bounty.Dispatch(r.Context(), tx, req.UnitIds); err != nil {
+		log.Printf("fail to dispatch bounty: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("bounty dispatch error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v dispatched units on bounty %v\n", userId, bountyId)
+	JsonRes(w, BountyDispatchRes{Bounty: bounty})
+}
+
+// Will claim the reward of a completed bounty. The dispatched units will no longer be busy.
+func (c Controller) BountyClaim(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	bountyId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "bounty ID should be an integer")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("bounty claim error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	bounty, err := FindBountyLock(r.Context(), tx, userId, bountyId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find bounty: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if bounty.IsClaimed {
+		ErrResCustom(w, http.StatusBadRequest, "bounty has already been claimed")
+		return
+	}
+
+	if !bounty.IsComplete() {
+		ErrResCustom(w, http.StatusBadRequest, "bounty is not complete")
+		return
+	}
+
+	if err := bounty.Claim(r.Context(), tx); err != nil {
+		log.Printf("fail to claim bounty: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := bounty.Reward.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to apply bounty reward: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("bounty claim error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v claimed bounty %v\n", userId, bountyId)
+	JsonRes(w, BountyClaimRes{Transaction: bounty.Reward})
+}
+
 /* Campaign Routes */
 
 func (c Controller) CampaignCollect(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
@@ -77,259 +379,1666 @@ func (c Controller) CampaignCollect(w http.ResponseWriter, r *http.Request, p ht
 	}
 	defer tx.Rollback(r.Context())
 
-	campaign := Campaign{UserId: userId}
-
-	query := "SELECT id, level, last_collected_at FROM campaign WHERE user_id = $1"
-	err = tx.QueryRow(r.Context(), query, userId).Scan(&campaign.Id, &campaign.Level, &campaign.LastCollectedAt)
+	// lock the campaign so concurrent collects can't both pay out the same idle time
+	campaign, err := FindCampaignLock(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find campaign row: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	prestige, err := FindPrestigeLock(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find prestige row: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	upgrades, err := FindIdleUpgradesLock(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find idle upgrades: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	modifiers := CreateCampaignModifiers(c.dataCache, prestige, upgrades)
+
+	transactions, err := campaign.Collect(r.Context(), tx, c.dataCache, c.rng, modifiers)
+	if err != nil {
+		log.Printf("fail to collect campaign resources: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	event := CampaignCollectEvent{UserEvent: UserEvent{UserId: userId}, Transactions: transactions}
+	if err := c.bus.Publish(r.Context(), tx, event); err != nil {
+		log.Printf("fail to publish event: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("campaign collect error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	res := CampaignCollectRes{
+		Transactions:    transactions,
+		LastCollectedAt: campaign.LastCollectedAt,
+	}
+
+	log.Printf("user %v has collected resources: %+v\n", userId, transactions)
+	JsonRes(w, res)
+}
+
+// Will battle a campaign stage with the given units or the default campaign team. Stages up to the current campaign level can be battled.
+// Winning the current stage will advance the campaign level. The best star rating of each stage is saved.
+func (c Controller) CampaignBattle(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	req := GetReqDto(r).(*CampaignBattleReq)
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("campaign battle error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	campaign, err := FindCampaignLock(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find campaign: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if req.Stage > campaign.Level {
+		ErrResCustom(w, http.StatusBadRequest, "stage has not been unlocked")
+		return
+	}
+
+	unitIds := req.UnitIds
+
+	// use the default campaign team if no units were given
+	if len(unitIds) == 0 {
+		unitIds, err = FindCampaignTeamUnitIds(r.Context(), tx, userId)
+		if err != nil {
+			log.Printf("fail to find campaign team: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if len(unitIds) == 0 {
+			ErrResCustom(w, http.StatusBadRequest, "no default campaign team")
+			return
+		}
+	}
+
+	units, err := FindUnitsLock(r.Context(), tx, userId, unitIds)
+	if err != nil {
+		log.Printf("fail to find units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if len(units) != len(unitIds) {
+		ErrResCustom(w, http.StatusBadRequest, "unit not found")
+		return
+	}
+
+	byId := make(map[uuid.UUID]Unit, len(units))
+	for _, unit := range units {
+		byId[unit.Id] = unit
+	}
+
+	// the units are found in database order, build the allies in the order of the team slots
+	allies := make([]BattleUnit, len(unitIds))
+	for i, id := range unitIds {
+		unit := byId[id]
+
+		if unit.IsBusy {
+			ErrResCustom(w, http.StatusBadRequest, "unit is busy")
+			return
+		}
+
+		allies[i] = CreateBattleUnit(c.dataCache, unit)
+	}
+
+	result := SimulateBattle(c.dataCache.TypeAdvantage, c.rng, allies, CampaignStageEnemies(c.dataCache, req.Stage))
+	stars := CampaignStars(result)
+
+	if result.Win {
+		if err := UpsertCampaignStageStars(r.Context(), tx, userId, req.Stage, stars); err != nil {
+			log.Printf("fail to save campaign stage stars: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if req.Stage == campaign.Level {
+			if err := campaign.IncreaseLevel(r.Context(), tx); err != nil {
+				log.Printf("fail to increase campaign level: %v\n", err)
+				ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+	}
+
+	event := CampaignBattleEvent{UserEvent: UserEvent{UserId: userId}, Stage: req.Stage, Win: result.Win}
+	if err := c.bus.Publish(r.Context(), tx, event); err != nil {
+		log.Printf("fail to publish event: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("campaign battle error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v battled campaign stage %v: %+v\n", userId, req.Stage, result)
+	JsonRes(w, CampaignBattleRes{Result: result, Stars: stars, Campaign: campaign})
+}
+
+// Will claim a chapter chest if enough stars have been earned in the chapter.
+func (c Controller) CampaignChestClaim(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	chapter, err := strconv.Atoi(p.ByName("chapter"))
+	if err != nil || chapter < 1 {
+		ErrResCustom(w, http.StatusBadRequest, "chapter should be a positive integer")
+		return
+	}
+
+	chestIndex, err := strconv.Atoi(p.ByName("chest"))
+	if err != nil || chestIndex < 0 || chestIndex >= len(CampaignChapterChests(chapter)) {
+		ErrRes(w, http.StatusNotFound)
+		return
+	}
+
+	chest := CampaignChapterChests(chapter)[chestIndex]
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("campaign chest claim error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	stars, err := FindCampaignChapterStars(r.Context(), tx, userId, chapter)
+	if err != nil {
+		log.Printf("fail to find campaign chapter stars: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if stars < chest.Stars {
+		ErrResCustom(w, http.StatusBadRequest, "not enough stars")
+		return
+	}
+
+	claimed, err := ClaimCampaignChest(r.Context(), tx, userId, chapter, chestIndex)
+	if err != nil {
+		log.Printf("fail to claim campaign chest: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !claimed {
+		ErrResCustom(w, http.StatusBadRequest, "chest has already been claimed")
+		return
+	}
+
+	if err := chest.Transaction.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to apply campaign chest reward: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("campaign chest claim error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v claimed campaign chapter %v chest %v\n", userId, chapter, chestIndex)
+	JsonRes(w, CampaignChestClaimRes{Transaction: chest.Transaction})
+}
+
+/* Chat Routes */
+
+// Will return the history of chat messages starting from the start parameter(starting message ID).
+func (c Controller) ChatMessageHistory(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	queryParams := r.URL.Query()
+	userId := GetUserId(r)
+	start := 0
+
+	// if start parameter is present, convert it to string and set start
+	if startParam := queryParams.Get("start"); startParam != "" {
+		var err error
+
+		start, err = strconv.Atoi(startParam)
+		if err != nil {
+			ErrResCustom(w, http.StatusBadRequest, "start should be an integer")
+			return
+		}
+	}
+
+	messages, err := GetChatMessages(r.Context(), c.db, start, CHAT_LOG_LEN)
+	if err != nil {
+		log.Printf("fail to get chat messages: %v", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v fetched chat history starting from message ID: %v", userId, start)
+
+	response := ChatMessageGetRes{Messages: messages}
+	JsonRes(w, response)
+}
+
+func (c Controller) ChatMessageSend(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	request := GetReqDto(r).(*ChatMessageSendReq)
+
+	// no need to lock, accuracy of username isn't important since names can be changed
+	userName, err := FindUserName(r.Context(), c.db, userId)
+	if err != nil {
+		log.Printf("fail to fetch name from users table: %v", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("chat message send error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	msgId, err := InsertChatMessage(r.Context(), tx, userId, userName, request.Message)
+	if err != nil {
+		log.Printf("fail to insert into chat_messages table: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	event := ChatSendEvent{UserEvent: UserEvent{UserId: userId}, MessageId: msgId}
+	if err := c.bus.Publish(r.Context(), tx, event); err != nil {
+		log.Printf("fail to publish event: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("chat message send error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	wsMsg := CreateWebSocketChatMessage(msgId, userId, userName, request.Message)
+	c.wsHub.broadcast <- wsMsg
+
+	log.Printf("user %v sent chat message: %v\n", userId, request.Message)
+
+	JsonSuccess(w)
+}
+
+/* Codex Routes */
+
+// Will return the user's codex. Every unit template is listed whether or not it has been discovered.
+func (c Controller) Codex(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	codex, err := FindCodex(r.Context(), c.db, c.dataCache, userId)
+	if err != nil {
+		log.Printf("fail to find codex: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, CodexRes{Codex: codex})
+}
+
+/* Daily Quest Routes */
+
+func (c Controller) DailyQuestComplete(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	questId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		log.Printf("daily quest complete, quest ID could not be converted to int: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	dailyQuest, ok := FindDailyQuest(c.dataCache, questId)
+	if !ok {
+		ErrRes(w, http.StatusNotFound)
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("daily quest complete, fail to begin transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	questProgress, err := FindDailyQuestProgress(r.Context(), tx, userId, questId)
+	if err != nil {
+		// quests added after the user's last sign in have no progress yet
+		if errors.Is(err, pgx.ErrNoRows) {
+			JsonRes(w, DailyQuestCompleteRes{Status: 2, Message: "requirements not met"})
+		} else {
+			log.Printf("fail to find daily quest progress: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if questProgress.IsCompleted() {
+		JsonRes(w, DailyQuestCompleteRes{Status: 1, Message: "already completed"})
+		return
+	}
+
+	if questProgress.Count < dailyQuest.Required {
+		JsonRes(w, DailyQuestCompleteRes{Status: 2, Message: "requirements not met"})
+		return
+	}
+
+	if err := questProgress.Complete(r.Context(), tx); err != nil {
+		log.Printf("fail to complete daily quest: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, reward := range dailyQuest.Rewards {
+		if err := reward.Apply(r.Context(), tx, userId); err != nil {
+			log.Printf("fail to apply daily quest reward: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("daily quest complete, failed to commit transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v completed daily quest %v", userId, questId)
+	res := DailyQuestCompleteRes{Status: 0, Rewards: dailyQuest.Rewards}
+	JsonRes(w, res)
+}
+
+/* Idle Upgrade Routes */
+
+// Will buy the next level of an idle upgrade with gold.
+func (c Controller) IdleUpgradeBuy(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	upgradeId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "upgrade ID should be an integer")
+		return
+	}
+
+	upgrade, ok := FindIdleUpgrade(c.dataCache, upgradeId)
+	if !ok {
+		ErrRes(w, http.StatusNotFound)
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("idle upgrade buy error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	level, err := FindIdleUpgradeLevelLock(r.Context(), tx, userId, upgradeId)
+	if err != nil {
+		log.Printf("fail to find idle upgrade level: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if level >= upgrade.MaxLevel {
+		ErrResCustom(w, http.StatusBadRequest, "upgrade is already at max level")
+		return
+	}
+
+	gold, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_GOLD)
+	if err != nil {
+		log.Printf("fail to find gold resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	cost := upgrade.Cost(level)
+
+	if gold.Amount < cost {
+		ErrResCustom(w, http.StatusBadRequest, "not enough gold")
+		return
+	}
+
+	transaction := Transaction{Type: TRANSACTION_GOLD, Amount: -cost}
+
+	if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to decrease gold resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := IncIdleUpgradeLevel(r.Context(), tx, userId, upgradeId); err != nil {
+		log.Printf("fail to increase idle upgrade level: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("idle upgrade buy error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v bought idle upgrade %v level %v\n", userId, upgradeId, level+1)
+	JsonRes(w, IdleUpgradeBuyRes{
+		Upgrade:     IdleUpgradeProgress{UpgradeId: upgradeId, Level: level + 1},
+		Transaction: transaction,
+	})
+}
+
+/* Item Routes */
+
+// Will return every item in the user's inventory.
+func (c Controller) ItemList(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	items, err := FindItems(r.Context(), c.db, userId)
+	if err != nil {
+		log.Printf("fail to find items: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, ItemsRes{Items: items})
+}
+
+// Will equip an item to a unit owned by the user. The item is moved if another unit is wearing it,
+// and the unit's item in the same slot is returned to the inventory.
+func (c Controller) ItemEquip(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	req := GetReqDto(r).(*ItemEquipReq)
+
+	itemId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "item ID should be an integer")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("item equip error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	unit, err := FindUnitLock(r.Context(), tx, userId, req.UnitId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrResCustom(w, http.StatusBadRequest, "unit not found")
+		} else {
+			log.Printf("fail to find unit: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	item, err := FindItemLock(r.Context(), tx, userId, itemId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find item: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if _, ok := FindItemTemplate(c.dataCache, item.Template); !ok {
+		log.Printf("item %v has invalid template %v\n", item.Id, item.Template)
+		ErrRes(w, http.StatusInternalServerError)
+		return
+	}
+
+	if err := item.Equip(r.Context(), tx, c.dataCache, unit.Id); err != nil {
+		log.Printf("fail to equip item: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	unit, err = FindUnitLock(r.Context(), tx, userId, unit.Id)
+	if err != nil {
+		log.Printf("fail to find unit: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("item equip error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	unit.Stats = CalcUnitStats(c.dataCache, unit)
+
+	log.Printf("user %v equipped item %v to unit %v\n", userId, item.Id, unit.Id)
+	JsonRes(w, ItemEquipRes{Item: item, Unit: unit})
+}
+
+// Will return an equipped item to the user's inventory.
+func (c Controller) ItemUnequip(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	itemId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "item ID should be an integer")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("item unequip error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	item, err := FindItemLock(r.Context(), tx, userId, itemId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find item: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if item.UnitId == nil {
+		ErrResCustom(w, http.StatusBadRequest, "item is not equipped")
+		return
+	}
+
+	if err := item.Unequip(r.Context(), tx); err != nil {
+		log.Printf("fail to unequip item: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("item unequip error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v unequipped item %v\n", userId, item.Id)
+	JsonSuccess(w)
+}
+
+/* Prestige Routes */
+
+// Will reset the campaign and prestige resources in exchange for a permanent campaign collect multiplier.
+func (c Controller) Prestige(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("prestige error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	prestige, err := FindPrestigeLock(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find prestige row: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	campaign, err := FindCampaignLock(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find campaign: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if campaign.Level < PRESTIGE_MIN_LEVEL {
+		ErrResCustom(w, http.StatusBadRequest, fmt.Sprintf("campaign level %v is required to prestige", PRESTIGE_MIN_LEVEL))
+		return
+	}
+
+	for _, resourceType := range PrestigeResetResources() {
+		if _, err := FindResourceLock(r.Context(), tx, userId, resourceType); err != nil {
+			log.Printf("fail to find resource: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if err := prestige.Reset(r.Context(), tx, &campaign); err != nil {
+		log.Printf("fail to reset prestige: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("prestige error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v has prestiged %v times\n", userId, prestige.Count)
+	JsonRes(w, PrestigeRes{Prestige: prestige, Campaign: campaign})
+}
+
+/* Roster Routes */
+
+// Will raise the user's unit capacity in exchange for gems.
+func (c Controller) RosterExpand(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("roster expand error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	roster, err := FindRosterLock(r.Context(), tx, c.dataCache, userId)
+	if err != nil {
+		log.Printf("fail to find roster: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if roster.Expansions >= c.dataCache.Roster.MaxExpansions {
+		ErrResCustom(w, http.StatusBadRequest, "unit roster is at max capacity")
+		return
+	}
+
+	gems, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_GEMS)
+	if err != nil {
+		log.Printf("fail to find gems resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	cost := c.dataCache.Roster.ExpansionCost
+
+	if gems.Amount < cost {
+		ErrResCustom(w, http.StatusBadRequest, "not enough gems")
+		return
+	}
+
+	transaction := Transaction{Type: TRANSACTION_GEMS, Amount: -cost}
+
+	if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to apply transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := roster.Expand(r.Context(), tx, c.dataCache, userId); err != nil {
+		log.Printf("fail to expand roster: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("roster expand error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v expanded their roster to %v units\n", userId, roster.Capacity)
+	JsonRes(w, RosterExpandRes{Roster: roster, Transaction: transaction})
+}
+
+/* Summon Routes */
+
+// Will return the active banners and the user's pity on every banner they have summoned on.
+func (c Controller) SummonBanners(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	pities, err := FindBannerPities(r.Context(), c.db, userId)
+	if err != nil {
+		log.Printf("fail to find banner pities: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, SummonBannersRes{Banners: ActiveBanners(c.dataCache, time.Now()), Pities: pities})
+}
+
+// Will return a page of the user's summon history, newest summons first.
+func (c Controller) SummonHistory(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	cursor, err := ParseSummonHistoryCursor(r.URL.Query())
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	records, nextCursor, err := FindSummonHistoryPage(r.Context(), c.db, userId, cursor)
+	if err != nil {
+		log.Printf("fail to find summon history: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, SummonHistoryRes{Records: records, NextCursor: nextCursor})
+}
+
+// Will summon multiple units at a discount on the banner given by the banner query parameter.
+// At least one unit is guaranteed to have the multi summon's guaranteed rarity.
+func (c Controller) SummonMulti(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	multi := c.dataCache.MultiSummon
+
+	bannerId, err := ParseBannerId(r.URL.Query())
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	banner, ok := FindBanner(c.dataCache, bannerId)
+	if !ok || !banner.IsActive(time.Now()) {
+		ErrResCustom(w, http.StatusBadRequest, "banner is not active")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("fail to begin transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	roster, err := FindRosterLock(r.Context(), tx, c.dataCache, userId)
+	if err != nil {
+		log.Printf("fail to find roster: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !roster.HasSpace(multi.Count) {
+		ErrResCode(w, http.StatusBadRequest, ERR_CODE_ROSTER_FULL, "unit roster is full")
+		return
+	}
+
+	gems, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_GEMS)
+	if err != nil {
+		log.Printf("fail to find gems resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if gems.Amount < multi.Cost() {
+		ErrResCustom(w, http.StatusBadRequest, "not enough gems")
+		return
+	}
+
+	transaction := Transaction{Type: TRANSACTION_GEMS, Amount: -multi.Cost()}
+
+	if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to apply transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	pity, err := FindBannerPityLock(r.Context(), tx, userId, banner.Id)
+	if err != nil {
+		log.Printf("fail to find banner pity: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	units, rewards, err := SummonUnits(r.Context(), tx, c.dataCache, c.rng, userId, banner, &pity, multi.Count, multi.GuaranteedRarity, transaction)
+	if err != nil {
+		log.Printf("fail to summon units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	event := UnitSummonEvent{UserEvent: UserEvent{UserId: userId}, BannerId: banner.Id, Templates: UnitTemplates(units)}
+	if err := c.bus.Publish(r.Context(), tx, event); err != nil {
+		log.Printf("fail to publish event: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("fail to commit transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v summoned %v units {bannerId:%v}\n", userId, len(units), banner.Id)
+	JsonRes(w, SummonMultiRes{Units: units, Transaction: transaction, Rewards: rewards, Pity: pity})
+}
+
+// Will return the chance of summoning every rarity and template.
+func (c Controller) SummonRates(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	JsonRes(w, SummonRatesRes{Rates: EffectiveSummonRates(c.dataCache)})
+}
+
+// Admin only. Will return the observed summon rate of every rarity compared with the configured rate.
+func (c Controller) SummonReport(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	rates, total, err := FindSummonRateReport(r.Context(), c.db, c.dataCache)
+	if err != nil {
+		log.Printf("fail to find summon rate report: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, SummonReportRes{Total: total, Rates: rates})
+}
+
+// Will summon a unit on the banner given by the banner query parameter, the standard banner is used by default.
+// The currency query parameter chooses whether gems, a summon ticket or the free summon pays for the unit.
+func (c Controller) SummonUnit(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	bannerId, err := ParseBannerId(r.URL.Query())
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currency, err := ParseSummonCurrency(r.URL.Query())
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	banner, ok := FindBanner(c.dataCache, bannerId)
+	if !ok || !banner.IsActive(time.Now()) {
+		ErrResCustom(w, http.StatusBadRequest, "banner is not active")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("fail to begin transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	roster, err := FindRosterLock(r.Context(), tx, c.dataCache, userId)
+	if err != nil {
+		log.Printf("fail to find roster: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !roster.HasSpace(1) {
+		ErrResCode(w, http.StatusBadRequest, ERR_CODE_ROSTER_FULL, "unit roster is full")
+		return
+	}
+
+	nextFreeSummonAt, err := FindNextFreeSummonLock(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to find next free summon: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// the free summon costs nothing, its own type keeps it apart from gem summons in the summon history
+	transaction := Transaction{Type: TRANSACTION_FREE_SUMMON}
+
+	switch currency {
+	case SUMMON_CURRENCY_FREE:
+		if time.Now().Before(nextFreeSummonAt) {
+			ErrResCustom(w, http.StatusBadRequest, "free summon is not available")
+			return
+		}
+
+		nextFreeSummonAt, err = UseFreeSummon(r.Context(), tx, userId)
+		if err != nil {
+			log.Printf("fail to use free summon: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+	case SUMMON_CURRENCY_TICKETS:
+		tickets, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_SUMMON_TICKET)
+		if err != nil {
+			log.Printf("fail to find summon ticket resource: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if tickets.Amount < 1 {
+			ErrResCustom(w, http.StatusBadRequest, "not enough summon tickets")
+			return
+		}
+
+		transaction = Transaction{Type: TRANSACTION_SUMMON_TICKETS, Amount: -1}
+
+	default:
+		gems, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_GEMS)
+		if err != nil {
+			log.Printf("fail to find gems resource: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if gems.Amount < UNIT_SUMMON_COST {
+			ErrResCustom(w, http.StatusBadRequest, "not enough gems")
+			return
+		}
+
+		transaction = Transaction{Type: TRANSACTION_GEMS, Amount: -UNIT_SUMMON_COST}
+	}
+
+	if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to apply transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	pity, err := FindBannerPityLock(r.Context(), tx, userId, banner.Id)
+	if err != nil {
+		log.Printf("fail to find banner pity: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	units, rewards, err := SummonUnits(r.Context(), tx, c.dataCache, c.rng, userId, banner, &pity, 1, RARITY_COMMON, transaction)
+	if err != nil {
+		log.Printf("fail to summon unit: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	event := UnitSummonEvent{UserEvent: UserEvent{UserId: userId}, BannerId: banner.Id, Templates: UnitTemplates(units)}
+	if err := c.bus.Publish(r.Context(), tx, event); err != nil {
+		log.Printf("fail to publish event: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	unit := units[0]
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("fail to commit transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v summoned a unit {templateId:%v bannerId:%v currency:%v}\n", userId, unit.Template, banner.Id, currency)
+	JsonRes(w, SummonUnitRes{
+		Unit:             unit,
+		Transaction:      transaction,
+		Rewards:          rewards,
+		Pity:             pity,
+		NextFreeSummonAt: nextFreeSummonAt,
+	})
+}
+
+/* Team Routes */
+
+// Will return every team saved by the user.
+func (c Controller) TeamList(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	teams, err := FindTeams(r.Context(), c.db, userId)
+	if err != nil {
+		log.Printf("fail to find teams: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, TeamsRes{Teams: teams})
+}
+
+// Will save a new team. Every unit in the team must be owned by the user.
+func (c Controller) TeamCreate(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	req := GetReqDto(r).(*TeamReq)
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("team create error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	count, err := CountTeams(r.Context(), tx, userId)
+	if err != nil {
+		log.Printf("fail to count teams: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if count >= TEAM_MAX_COUNT {
+		ErrResCustom(w, http.StatusBadRequest, fmt.Sprintf("cannot have more than %v teams", TEAM_MAX_COUNT))
+		return
+	}
+
+	team := Team{UserId: userId, Name: req.Name, Units: req.Units}
+
+	units, err := FindUnitsLock(r.Context(), tx, userId, team.UnitIds())
+	if err != nil {
+		log.Printf("fail to find units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if len(units) != len(team.Units) {
+		ErrResCustom(w, http.StatusBadRequest, "unit not found")
+		return
+	}
+
+	if err := InsertTeam(r.Context(), tx, &team); err != nil {
+		log.Printf("fail to insert team: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("team create error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v created team %v\n", userId, team.Id)
+	JsonRes(w, TeamRes{Team: team})
+}
+
+// Will replace the name and units of a team.
+func (c Controller) TeamUpdate(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	req := GetReqDto(r).(*TeamReq)
+
+	teamId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "team ID should be an integer")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("team update error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	team, err := FindTeamLock(r.Context(), tx, userId, teamId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find team: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	team.Name = req.Name
+	team.Units = req.Units
+
+	units, err := FindUnitsLock(r.Context(), tx, userId, team.UnitIds())
+	if err != nil {
+		log.Printf("fail to find units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if len(units) != len(team.Units) {
+		ErrResCustom(w, http.StatusBadRequest, "unit not found")
+		return
+	}
+
+	if err := team.Update(r.Context(), tx); err != nil {
+		log.Printf("fail to update team: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("team update error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v updated team %v\n", userId, team.Id)
+	JsonRes(w, TeamRes{Team: team})
+}
+
+func (c Controller) TeamDelete(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	teamId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "team ID should be an integer")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("team delete error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	deleted, err := DeleteTeam(r.Context(), tx, userId, teamId)
+	if err != nil {
+		log.Printf("fail to delete team: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !deleted {
+		ErrRes(w, http.StatusNotFound)
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("team delete error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v deleted team %v\n", userId, teamId)
+	JsonSuccess(w)
+}
+
+// Will make the team the default team used in campaign battles.
+func (c Controller) TeamSetCampaignDefault(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	teamId, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "team ID should be an integer")
+		return
+	}
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("team set campaign default error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	team, err := FindTeamLock(r.Context(), tx, userId, teamId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find team: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if err := team.SetCampaignDefault(r.Context(), tx); err != nil {
+		log.Printf("fail to set campaign default team: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("team set campaign default error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v set team %v as the campaign default\n", userId, teamId)
+	JsonSuccess(w)
+}
+
+/* Unit Routes */
+
+// Will return a page of the user's units. Units can be filtered by template, type, stars and lock status,
+// and sorted by level, stars or power. Pass the next cursor from the previous page to fetch the next page.
+func (c Controller) UnitList(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	query, err := ParseUnitQuery(r.URL.Query())
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	units, nextCursor, err := FindUnitsPage(r.Context(), c.db, c.dataCache, userId, query)
+	if err != nil {
+		log.Printf("fail to find units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	JsonRes(w, UnitsRes{Units: units, NextCursor: nextCursor})
+}
+
+// Will return a unit owned by the user along with its computed stats.
+func (c Controller) UnitDetail(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+
+	unitId, err := uuid.Parse(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "invalid unit ID")
+		return
+	}
+
+	unit, err := FindUnit(r.Context(), c.db, c.dataCache, userId, unitId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find unit: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	JsonRes(w, UnitRes{Unit: unit})
+}
+
+// Will lock or unlock every unit in the request. The batch is rejected if any unit is not owned by the user.
+func (c Controller) UnitBulkLock(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	userId := GetUserId(r)
+	req := GetReqDto(r).(*UnitBulkLockReq)
+
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("unit bulk lock error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	units, err := FindUnitsLock(r.Context(), tx, userId, req.UnitIds)
 	if err != nil {
-		log.Printf("fail to find campaign row: %v\n", err)
+		log.Printf("fail to find units: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	transactions, err := campaign.Collect(r.Context(), tx)
-	if err != nil {
-		log.Printf("fail to collect campaign resources: %v\n", err)
+	if len(units) != len(req.UnitIds) {
+		ErrResCustom(w, http.StatusBadRequest, "unit not found")
+		return
+	}
+
+	if err := SetUnitsLocked(r.Context(), tx, userId, req.UnitIds, req.Locked); err != nil {
+		log.Printf("fail to set units locked: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	if err := tx.Commit(r.Context()); err != nil {
-		log.Printf("campaign collect error: %v\n", err)
+		log.Printf("unit bulk lock error: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	res := CampaignCollectRes{
-		Transactions:    transactions,
-		LastCollectedAt: campaign.LastCollectedAt,
+	results := make([]UnitResult, len(units))
+	for i, unit := range units {
+		results[i] = UnitResult{UnitId: unit.Id, Success: true}
 	}
 
-	log.Printf("user %v has collected resources: %+v\n", userId, transactions)
-	JsonRes(w, res)
+	log.Printf("user %v set locked to %v for %v units\n", userId, req.Locked, len(units))
+	JsonRes(w, UnitBulkLockRes{Results: results})
 }
 
-/* Chat Routes */
-
-// Will return the history of chat messages starting from the start parameter(starting message ID).
-func (c Controller) ChatMessageHistory(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-	queryParams := r.URL.Query()
+// Toggle a unit's lock. Only works on units owned by the user.
+func (c Controller) UnitToggleLock(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	userId := GetUserId(r)
-	start := 0
 
-	// if start parameter is present, convert it to string and set start
-	if startParam := queryParams.Get("start"); startParam != "" {
-		var err error
+	unitId, err := uuid.Parse(p.ByName("id"))
+	if err != nil {
+		log.Printf("invalid unit ID: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
-		start, err = strconv.Atoi(startParam)
-		if err != nil {
-			ErrResCustom(w, http.StatusBadRequest, "start should be an integer")
-			return
-		}
+	tx, err := c.db.Begin(r.Context())
+	if err != nil {
+		log.Printf("fail to begin transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
 	}
+	defer tx.Rollback(r.Context())
 
-	messages, err := GetChatMessages(r.Context(), c.db, start, CHAT_LOG_LEN)
+	query := "UPDATE units SET is_locked = NOT is_locked WHERE id = $1"
+	cmdTag, err := c.db.Exec(r.Context(), query, unitId)
 	if err != nil {
-		log.Printf("fail to get chat messages: %v", err)
+		log.Printf("fail to update units table: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	log.Printf("user %v fetched chat history starting from message ID: %v", userId, start)
+	if cmdTag.RowsAffected() > 0 {
+		if err := tx.Commit(r.Context()); err != nil {
+			log.Printf("fail to commit transaction: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	} else {
+		ErrRes(w, http.StatusNotFound)
+		return
+	}
 
-	response := ChatMessageGetRes{Messages: messages}
-	JsonRes(w, response)
+	log.Printf("user %v toggled lock for unit %v", userId, unitId)
+	JsonSuccess(w)
 }
 
-func (c Controller) ChatMessageSend(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+// Will raise a unit's stars by consuming fodder units and evo stones. Locked and busy units cannot be fodder.
+func (c Controller) UnitEvolve(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	userId := GetUserId(r)
-	request := GetReqDto(r).(*ChatMessageSendReq)
+	req := GetReqDto(r).(*UnitEvolveReq)
 
-	// no need to lock, accuracy of username isn't important since names can be changed
-	userName, err := FindUserName(r.Context(), c.db, userId)
+	unitId, err := uuid.Parse(p.ByName("id"))
 	if err != nil {
-		log.Printf("fail to fetch name from users table: %v", err)
-		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		ErrResCustom(w, http.StatusBadRequest, "invalid unit ID")
 		return
 	}
 
-	msgId, err := InsertChatMessage(r.Context(), c.db, userId, userName, request.Message)
+	tx, err := c.db.Begin(r.Context())
 	if err != nil {
-		log.Printf("fail to insert into chat_messages table: %v\n", err)
+		log.Printf("unit evolve error: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	defer tx.Rollback(r.Context())
 
-	wsMsg := CreateWebSocketChatMessage(msgId, userId, userName, request.Message)
-	c.wsHub.broadcast <- wsMsg
+	unit, err := FindUnitLock(r.Context(), tx, userId, unitId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find unit: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
 
-	log.Printf("user %v sent chat message: %v\n", userId, request.Message)
+	if unit.IsBusy {
+		ErrResCustom(w, http.StatusBadRequest, "unit is busy")
+		return
+	}
 
-	JsonSuccess(w)
-}
+	tier, ok := c.dataCache.UnitProgression.EvolveTier(unit.Stars)
+	if !ok {
+		ErrResCustom(w, http.StatusBadRequest, "unit is already at max stars")
+		return
+	}
 
-/* Daily Quest Routes */
+	if len(req.FodderIds) != tier.FodderCount {
+		ErrResCustom(w, http.StatusBadRequest, fmt.Sprintf("evolve requires %v fodder units", tier.FodderCount))
+		return
+	}
 
-func (c Controller) DailyQuestComplete(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-	userId := GetUserId(r)
+	for _, id := range req.FodderIds {
+		if id == unit.Id {
+			ErrResCustom(w, http.StatusBadRequest, "unit cannot be its own fodder")
+			return
+		}
+	}
 
-	questId, err := strconv.Atoi(p.ByName("id"))
+	fodder, err := FindUnitsLock(r.Context(), tx, userId, req.FodderIds)
 	if err != nil {
-		log.Printf("daily quest complete, quest ID could not be converted to int: %v\n", err)
+		log.Printf("fail to find fodder units: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	tx, err := c.db.Begin(r.Context())
-	if err != nil {
-		log.Printf("daily quest complete, fail to begin transaction: %v\n", err)
-		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+	if len(fodder) != len(req.FodderIds) {
+		ErrResCustom(w, http.StatusBadRequest, "unit not found")
 		return
 	}
-	defer tx.Rollback(r.Context())
 
-	questProgress, err := FindDailyQuestProgress(r.Context(), tx, userId, questId)
+	for _, f := range fodder {
+		if err := tier.CheckFodder(c.dataCache, unit, f); err != nil {
+			ErrResCustom(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	evoStones, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_EVO_STONE)
 	if err != nil {
-		log.Printf("fail to find daily quest progress: %v\n", err)
+		log.Printf("fail to find evo stone resource: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	dailyQuest := c.dataCache.DailyQuests[questProgress.DailyQuestId]
+	if evoStones.Amount < tier.EvoStones {
+		ErrResCustom(w, http.StatusBadRequest, "not enough evo stones")
+		return
+	}
 
-	if questProgress.IsCompleted() {
-		JsonRes(w, DailyQuestCompleteRes{Status: 1, Message: "already completed"})
+	transaction := Transaction{Type: TRANSACTION_EVO_STONES, Amount: -tier.EvoStones}
+
+	if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+		log.Printf("fail to decrease evo stone resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if questProgress.Count < dailyQuest.Required {
-		JsonRes(w, DailyQuestCompleteRes{Status: 2, Message: "requirements not met"})
+	if err := DeleteUnits(r.Context(), tx, userId, req.FodderIds); err != nil {
+		log.Printf("fail to delete fodder units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if err := questProgress.Complete(r.Context(), tx); err != nil {
-		log.Printf("fail to complete daily quest: %v\n", err)
+	unit.Stars++
+	unit.Stats = CalcUnitStats(c.dataCache, unit)
+
+	if err := unit.UpdateStars(r.Context(), tx); err != nil {
+		log.Printf("fail to update unit stars: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if err := dailyQuest.Transaction.Apply(r.Context(), tx, userId); err != nil {
-		log.Printf("fail to apply daily quest reward: %v\n", err)
+	if _, err := UpdateCodex(r.Context(), tx, userId, unit); err != nil {
+		log.Printf("fail to update codex: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	if err := tx.Commit(r.Context()); err != nil {
-		log.Printf("daily quest complete, failed to commit transaction: %v\n", err)
+		log.Printf("unit evolve error: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	log.Printf("user %v completed daily quest %v", userId, questId)
-	res := DailyQuestCompleteRes{Status: 0, Transaction: dailyQuest.Transaction}
-	JsonRes(w, res)
+	log.Printf("user %v evolved unit %v to %v stars\n", userId, unitId, unit.Stars)
+	JsonRes(w, UnitEvolveRes{Unit: unit, Transaction: transaction})
 }
 
-/* Summon Routes */
-
-func (c Controller) SummonUnit(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+// Will raise a unit's level using gold and exp stones. The unit's level cannot exceed the level cap of its stars.
+func (c Controller) UnitLevelUp(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	userId := GetUserId(r)
+	req := GetReqDto(r).(*UnitLevelUpReq)
+
+	unitId, err := uuid.Parse(p.ByName("id"))
+	if err != nil {
+		ErrResCustom(w, http.StatusBadRequest, "invalid unit ID")
+		return
+	}
 
 	tx, err := c.db.Begin(r.Context())
 	if err != nil {
-		log.Printf("fail to begin transaction: %v\n", err)
+		log.Printf("unit level up error: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 	defer tx.Rollback(r.Context())
 
-	resource, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_GEMS)
+	unit, err := FindUnitLock(r.Context(), tx, userId, unitId)
 	if err != nil {
-		log.Printf("fail to find gems resource: %v\n", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			ErrRes(w, http.StatusNotFound)
+		} else {
+			log.Printf("fail to find unit: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	progression := c.dataCache.UnitProgression
+
+	if unit.Level+req.Levels > progression.LevelCap(unit.Stars) {
+		ErrResCustom(w, http.StatusBadRequest, fmt.Sprintf("unit level cannot exceed %v", progression.LevelCap(unit.Stars)))
+		return
+	}
+
+	goldCost, expStonesCost := progression.LevelUpCost(unit.Level, req.Levels)
+
+	gold, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_GOLD)
+	if err != nil {
+		log.Printf("fail to find gold resource: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// must have enough resources to summon
-	if resource.Amount < UNIT_SUMMON_COST {
-		ErrResCustom(w, http.StatusBadRequest, "not enough gems")
+	expStones, err := FindResourceLock(r.Context(), tx, userId, RESOURCE_EXP_STONE)
+	if err != nil {
+		log.Printf("fail to find exp stone resource: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if gold.Amount < goldCost {
+		ErrResCustom(w, http.StatusBadRequest, "not enough gold")
+		return
+	}
+
+	if expStones.Amount < expStonesCost {
+		ErrResCustom(w, http.StatusBadRequest, "not enough exp stones")
 		return
 	}
 
-	resource.Amount -= UNIT_SUMMON_COST
+	transactions := []Transaction{
+		{Type: TRANSACTION_GOLD, Amount: -goldCost},
+		{Type: TRANSACTION_EXP_STONES, Amount: -expStonesCost},
+	}
+
+	for _, transaction := range transactions {
+		if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+			log.Printf("fail to apply unit level up cost: %v\n", err)
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
 
-	unit := RandUnit(c.dataCache)
+	unit.Level += req.Levels
+	unit.Stats = CalcUnitStats(c.dataCache, unit)
 
-	if err := InsertUnit(r.Context(), tx, userId, unit); err != nil {
-		log.Printf("fail to insert unit: %v\n", err)
+	if err := unit.UpdateLevel(r.Context(), tx); err != nil {
+		log.Printf("fail to update unit level: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	if err := IncResource(r.Context(), tx, userId, RESOURCE_GEMS, -UNIT_SUMMON_COST); err != nil {
-		log.Printf("fail to increase gems resource: %v\n", err)
+	event := UnitLevelUpEvent{UserEvent: UserEvent{UserId: userId}, UnitId: unit.Id, Levels: req.Levels}
+	if err := c.bus.Publish(r.Context(), tx, event); err != nil {
+		log.Printf("fail to publish event: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	if err := tx.Commit(r.Context()); err != nil {
-		log.Printf("fail to commit transaction: %v\n", err)
+		log.Printf("unit level up error: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	log.Printf("user %v summoned a unit {templateId:%v}\n", userId, unit.Template)
-	JsonRes(w, SummonUnitRes{
-		Unit: unit,
-		Transaction: Transaction{
-			Type:   TRANSACTION_GEMS,
-			Amount: -UNIT_SUMMON_COST,
-		},
-	})
+	log.Printf("user %v leveled up unit %v to level %v\n", userId, unitId, unit.Level)
+	JsonRes(w, UnitLevelUpRes{Unit: unit, Transactions: transactions})
 }
 
-/* Unit Routes */
-
-// Toggle a unit's lock. Only works on units owned by the user.
-func (c Controller) UnitToggleLock(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+// Will delete the units and refund part of the resources invested in them. Locked, busy, and team units
+// are skipped and reported in the results. The batch is rejected if any unit is not owned by the user.
+func (c Controller) UnitRetire(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 	userId := GetUserId(r)
+	req := GetReqDto(r).(*UnitRetireReq)
 
-	unitId, err := uuid.Parse(p.ByName("id"))
+	tx, err := c.db.Begin(r.Context())
 	if err != nil {
-		log.Printf("invalid unit ID: %v\n", err)
+		log.Printf("unit retire error: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	defer tx.Rollback(r.Context())
 
-	tx, err := c.db.Begin(r.Context())
+	units, err := FindUnitsLock(r.Context(), tx, userId, req.UnitIds)
 	if err != nil {
-		log.Printf("fail to begin transaction: %v\n", err)
+		log.Printf("fail to find units: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer tx.Rollback(r.Context())
 
-	query := "UPDATE units SET is_locked = NOT is_locked WHERE id = $1"
-	cmdTag, err := c.db.Exec(r.Context(), query, unitId)
-	if err != nil {
-		log.Printf("fail to update units table: %v\n", err)
-		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+	if len(units) != len(req.UnitIds) {
+		ErrResCustom(w, http.StatusBadRequest, "unit not found")
 		return
 	}
 
-	if cmdTag.RowsAffected() > 0 {
-		if err := tx.Commit(r.Context()); err != nil {
-			log.Printf("fail to commit transaction: %v\n", err)
+	refund := make([]Transaction, 0)
+	retired := make([]uuid.UUID, 0, len(units))
+	results := make([]UnitResult, len(units))
+
+	for i, unit := range units {
+		results[i] = UnitResult{UnitId: unit.Id}
+
+		if err := unit.CheckRetire(); err != nil {
+			results[i].Message = err.Error()
+			continue
+		}
+
+		results[i].Success = true
+		retired = append(retired, unit.Id)
+		refund = append(refund, c.dataCache.UnitProgression.RetireRefund(unit)...)
+	}
+
+	transactions := MergeTransactions(refund)
+
+	for _, transaction := range transactions {
+		if err := transaction.Apply(r.Context(), tx, userId); err != nil {
+			log.Printf("fail to apply unit retire refund: %v\n", err)
 			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-	} else {
-		ErrRes(w, http.StatusNotFound)
+	}
+
+	if err := DeleteUnits(r.Context(), tx, userId, retired); err != nil {
+		log.Printf("fail to delete units: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	log.Printf("user %v toggled lock for unit %v", userId, unitId)
-	JsonSuccess(w)
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("unit retire error: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	log.Printf("user %v retired %v units: %+v\n", userId, len(retired), transactions)
+	JsonRes(w, UnitRetireRes{Transactions: transactions, Results: results})
 }
 
 /* User Routes */
@@ -449,30 +2158,24 @@ func (c Controller) SignIn(w http.ResponseWriter, r *http.Request, p httprouter.
 	}
 	defer tx.Rollback(r.Context())
 
-	// daily sign in quest progress
-	questProgress := DailyQuestProgress{DailyQuestId: DAILY_QUEST_SIGN_IN, UserId: user.Id}
-
-	query = "SELECT id, last_completed_at FROM daily_quest_progress WHERE (user_id = $1 AND daily_quest_id = $2) FOR UPDATE"
-	err = tx.QueryRow(r.Context(), query, user.Id, questProgress.DailyQuestId).Scan(&questProgress.Id, &questProgress.LastCompletedAt)
-	if err != nil {
-		log.Printf("fail to query daily_quest_progress table: %v\n", err)
+	// quests may have been added since the user signed up
+	if err := InsertDailyQuestProgress(r.Context(), tx, c.dataCache, user.Id); err != nil {
+		log.Printf("fail to insert daily quest progress: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// increase sign in quest count if not completed
-	if !questProgress.IsCompleted() {
-		if err := questProgress.IncreaseCount(r.Context(), tx); err != nil {
-			log.Printf("fail to increase daily quest progress count: %v\n", err)
-			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
-			return
-		}
+	event := SignInEvent{UserEvent: UserEvent{UserId: user.Id}}
+	if err := c.bus.Publish(r.Context(), tx, event); err != nil {
+		log.Printf("fail to publish event: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
-		if err := tx.Commit(r.Context()); err != nil {
-			log.Printf("fail to commit transaction: %v\n", err)
-			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
-			return
-		}
+	if err := tx.Commit(r.Context()); err != nil {
+		log.Printf("fail to commit transaction: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
 	}
 
 	campaign, err := FindCampaign(r.Context(), c.db, user.Id)
@@ -482,6 +2185,20 @@ func (c Controller) SignIn(w http.ResponseWriter, r *http.Request, p httprouter.
 		return
 	}
 
+	achievementProgress, err := FindAchievementProgress(r.Context(), c.db, c.dataCache, user.Id)
+	if err != nil {
+		log.Printf("fail to find achievement progress: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	campaignChapters, err := FindCampaignChapters(r.Context(), c.db, campaign)
+	if err != nil {
+		log.Printf("fail to find campaign chapters: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	dailyQuestProgress, err := FindAllDailyQuestProgress(r.Context(), c.db, user.Id)
 	if err != nil {
 		log.Printf("fail to find daily quest progress: %v\n", err)
@@ -489,6 +2206,27 @@ func (c Controller) SignIn(w http.ResponseWriter, r *http.Request, p httprouter.
 		return
 	}
 
+	idleUpgrades, err := FindIdleUpgrades(r.Context(), c.db, user.Id)
+	if err != nil {
+		log.Printf("fail to find idle upgrades: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items, err := FindItems(r.Context(), c.db, user.Id)
+	if err != nil {
+		log.Printf("fail to find items: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	prestige, err := FindPrestige(r.Context(), c.db, user.Id)
+	if err != nil {
+		log.Printf("fail to find prestige: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	resources, err := FindResources(r.Context(), c.db, user.Id)
 	if err != nil {
 		log.Printf("fail to find resources: %v\n", err)
@@ -496,21 +2234,47 @@ func (c Controller) SignIn(w http.ResponseWriter, r *http.Request, p httprouter.
 		return
 	}
 
-	units, err := FindUnits(r.Context(), c.db, user.Id)
+	units, err := FindUnits(r.Context(), c.db, c.dataCache, user.Id)
 	if err != nil {
 		log.Printf("fail to find units: %v\n", err)
 		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	roster, err := FindRoster(r.Context(), c.db, c.dataCache, user.Id)
+	if err != nil {
+		log.Printf("fail to find roster: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	nextFreeSummonAt, err := FindNextFreeSummon(r.Context(), c.db, user.Id)
+	if err != nil {
+		log.Printf("fail to find next free summon: %v\n", err)
+		ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	signInRes := SignInRes{
-		Token:              token,
-		User:               user,
-		Campaign:           campaign,
-		DailyQuestProgress: dailyQuestProgress,
-		Resources:          resources,
-		Units:              units,
-		UnitTemplates:      c.dataCache.UnitTemplates,
+		Token:               token,
+		User:                user,
+		AchievementProgress: achievementProgress,
+		Campaign:            campaign,
+		CampaignChapters:    campaignChapters,
+		DailyQuestProgress:  dailyQuestProgress,
+		IdleUpgrades:        idleUpgrades,
+		Items:               items,
+		Prestige:            prestige,
+		Resources:           resources,
+		Roster:              roster,
+		Units:               units,
+		UnitTemplates:       c.dataCache.UnitTemplates,
+		AchievementData:     c.dataCache.Achievements,
+		DailyQuestData:      c.dataCache.DailyQuests,
+		IdleUpgradeData:     c.dataCache.IdleUpgrades,
+		ItemData:            c.dataCache.Items,
+		TypeAdvantageData:   c.dataCache.TypeAdvantage,
+		NextFreeSummonAt:    nextFreeSummonAt,
 	}
 
 	log.Printf("user sign in: {id:%v name:%v email:%v}\n", user.Id, user.Name, user.Email)
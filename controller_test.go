@@ -8,10 +8,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v4"
 	"golang.org/x/crypto/bcrypt"
 
@@ -81,6 +83,174 @@ func TestNotFoundRoute(t *testing.T) {
 	}
 }
 
+/* Achievement Routes */
+
+func TestAchievementRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	achievementId := AchievementsByObjective(idlemonServer.DataCache, ACHIEVEMENT_SEND_CHAT)[0]
+	achievement, _ := FindAchievement(idlemonServer.DataCache, achievementId)
+	url := fmt.Sprintf("/achievement/%v/claim", achievementId)
+
+	// not reached yet
+	response := SendRequest(t, "PUT", url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// the chat send event progresses the achievement
+	for i := 0; i < achievement.Required; i++ {
+		response = SendRequest(t, "POST", "/chat/message/send", user.Id, token, &ChatMessageSendReq{Message: "hello"})
+		body = ReadResponseBody(t, response)
+
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+		}
+	}
+
+	response = SendRequest(t, "GET", "/achievement", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var listRes AchievementListRes
+
+	if err := json.Unmarshal([]byte(body), &listRes); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if len(listRes.Progress) != len(idlemonServer.DataCache.Achievements) {
+		t.Fatalf("expect progress on %v achievements, receive: %v", len(idlemonServer.DataCache.Achievements), len(listRes.Progress))
+	}
+
+	for _, progress := range listRes.Progress {
+		if progress.AchievementId == achievementId && progress.Count != achievement.Required {
+			t.Fatalf("expect count %v, receive: %v", achievement.Required, progress.Count)
+		}
+	}
+
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var claimRes AchievementClaimRes
+
+	if err := json.Unmarshal([]byte(body), &claimRes); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if !reflect.DeepEqual(claimRes.Rewards, achievement.Rewards) {
+		t.Fatalf("unexpected rewards, expect: %+v, receive: %+v", achievement.Rewards, claimRes.Rewards)
+	}
+
+	// rewards can only be claimed once
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// unknown achievement
+	response = SendRequest(t, "PUT", "/achievement/9999/claim", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect status 404, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+/* Bounty Routes */
+
+func TestBountyRoutes(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+
+	// fetch the bounty board
+	response := SendRequest(t, "GET", "/bounty-board", user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var boardRes BountyBoardRes
+
+	if err := json.Unmarshal([]byte(body), &boardRes); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if len(boardRes.Bounties) != BOUNTY_BOARD_SIZE {
+		t.Fatalf("expect %v bounties, receive: %v", BOUNTY_BOARD_SIZE, len(boardRes.Bounties))
+	}
+
+	// make the first bounty accept any single unit
+	bountyId := boardRes.Bounties[0].Id
+
+	query := "UPDATE bounties SET unit_type = $1, min_stars = 1, unit_count = 1 WHERE id = $2"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, BOUNTY_ANY_UNIT_TYPE, bountyId); err != nil {
+		t.Fatalf("fail to update bounties table: %v", err)
+	}
+
+	// dispatch the unit
+	url := fmt.Sprintf("/bounty/%v/dispatch", bountyId)
+	response = SendRequest(t, "PUT", url, user.Id, token, &BountyDispatchReq{UnitIds: []uuid.UUID{unit.Id}})
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var bountyId2 *int
+
+	query = "SELECT bounty_id FROM units WHERE id = $1"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, unit.Id).Scan(&bountyId2); err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if bountyId2 == nil || *bountyId2 != bountyId {
+		t.Fatalf("unit should be busy on bounty %v, receive: %v", bountyId, bountyId2)
+	}
+
+	// bounty is not complete yet
+	url = fmt.Sprintf("/bounty/%v/claim", bountyId)
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// complete the bounty by moving the start time into the past
+	query = "UPDATE bounties SET started_at = $1 WHERE id = $2"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, time.Now().Add(-time.Hour*24), bountyId); err != nil {
+		t.Fatalf("fail to update bounties table: %v", err)
+	}
+
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// unit should no longer be busy
+	query = "SELECT bounty_id FROM units WHERE id = $1"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, unit.Id).Scan(&bountyId2); err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if bountyId2 != nil {
+		t.Fatalf("unit should not be busy, receive bounty ID: %v", *bountyId2)
+	}
+}
+
 /* Campaign Routes */
 
 func TestCampaignCollectRoute(t *testing.T) {
@@ -98,6 +268,11 @@ func TestCampaignCollectRoute(t *testing.T) {
 		t.Fatalf("fail to unmarshal response body: %v", err)
 	}
 
+	// no drops should be rolled since no time has passed
+	if len(res.Transactions) != 3 {
+		t.Fatalf("expect 3 transactions, receive: %v", len(res.Transactions))
+	}
+
 	if res.Transactions[0].Amount != 0 {
 		t.Fatalf("collected resources should be 0: %v", res)
 	}
@@ -111,6 +286,119 @@ func TestCampaignCollectRoute(t *testing.T) {
 	}
 }
 
+func TestCampaignBattleRoute(t *testing.T) {
+	method := "PUT"
+	url := "/campaign/battle"
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	unitIds := make([]uuid.UUID, BATTLE_MAX_TEAM_SIZE)
+	for i := range unitIds {
+		unitIds[i] = InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id).Id
+	}
+
+	// stages past the campaign level are locked
+	request := &CampaignBattleReq{Stage: 2, UnitIds: unitIds}
+	response := SendRequest(t, method, url, user.Id, token, request)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// full team should beat the first stage
+	request.Stage = 1
+	response = SendRequest(t, method, url, user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res CampaignBattleRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if !res.Result.Win {
+		t.Fatalf("expect battle to be won: %+v", res.Result)
+	}
+
+	if res.Stars < 1 || res.Stars > CAMPAIGN_MAX_STARS {
+		t.Fatalf("invalid stars, receive: %v", res.Stars)
+	}
+
+	// campaign level and stage stars should be updated
+	var level, stars int
+
+	query := "SELECT level FROM campaign WHERE user_id = $1"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id).Scan(&level); err != nil {
+		t.Fatalf("fail to query campaign table: %v", err)
+	}
+
+	if level != 2 {
+		t.Fatalf("expect campaign level 2, receive: %v", level)
+	}
+
+	query = "SELECT stars FROM campaign_stages WHERE (user_id = $1 AND stage = 1)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id).Scan(&stars); err != nil {
+		t.Fatalf("fail to query campaign_stages table: %v", err)
+	}
+
+	if stars != res.Stars {
+		t.Fatalf("expect stars in database to equal %v, receive: %v", res.Stars, stars)
+	}
+}
+
+func TestCampaignChestClaimRoute(t *testing.T) {
+	method := "PUT"
+	url := "/campaign/chapter/1/chest/0"
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// no stars have been earned
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// give the user 3 stars on every stage of the first chapter
+	for stage := 1; stage <= CAMPAIGN_CHAPTER_STAGES; stage++ {
+		query := "INSERT INTO campaign_stages (user_id, stage, stars) VALUES ($1, $2, $3)"
+		if _, err := idlemonServer.Db.Exec(context.Background(), query, user.Id, stage, CAMPAIGN_MAX_STARS); err != nil {
+			t.Fatalf("fail to insert campaign_stages row: %v", err)
+		}
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res CampaignChestClaimRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Transaction != CampaignChapterChests(1)[0].Transaction {
+		t.Fatalf("unexpected transaction, expect: %v, receive: %v", CampaignChapterChests(1)[0].Transaction, res.Transaction)
+	}
+
+	// chest can only be claimed once
+	response = SendRequest(t, method, url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
 /* Chat Routs */
 
 func TestChatMessageSendRoute(t *testing.T) {
@@ -205,7 +493,8 @@ func TestChatMessageSendRoute(t *testing.T) {
 
 func TestDailyQuestComplete(t *testing.T) {
 	method := "PUT"
-	url := fmt.Sprintf("/daily-quest/%v/complete", DAILY_QUEST_SIGN_IN)
+	quest, _ := FindDailyQuest(idlemonServer.DataCache, DailyQuestsByObjective(idlemonServer.DataCache, DAILY_QUEST_SIGN_IN)[0])
+	url := fmt.Sprintf("/daily-quest/%v/complete", quest.Id)
 
 	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
 
@@ -232,7 +521,7 @@ func TestDailyQuestComplete(t *testing.T) {
 
 	// set quest progress to 100% to test successful completion
 	query := "UPDATE daily_quest_progress SET count = 1 WHERE (user_id = $1 AND daily_quest_id = $2)"
-	_, err := idlemonServer.Db.Exec(context.Background(), query, user.Id, DAILY_QUEST_SIGN_IN)
+	_, err := idlemonServer.Db.Exec(context.Background(), query, user.Id, quest.Id)
 	if err != nil {
 		t.Fatalf("fail to update daily_quest_progress table: %v", err)
 	}
@@ -256,14 +545,15 @@ func TestDailyQuestComplete(t *testing.T) {
 		t.Fatalf("expect response status to equal 0, receive: %v", questCompleteRes.Status)
 	}
 
-	expect := idlemonServer.DataCache.DailyQuests[DAILY_QUEST_SIGN_IN].Transaction.Type
-	if questCompleteRes.Transaction.Type != expect {
-		t.Fatalf("unexpected transaction type, expect: %v, receive: %v", expect, questCompleteRes.Transaction.Type)
+	if !reflect.DeepEqual(questCompleteRes.Rewards, quest.Rewards) {
+		t.Fatalf("unexpected rewards, expect: %+v, receive: %+v", quest.Rewards, questCompleteRes.Rewards)
 	}
 
-	expect = idlemonServer.DataCache.DailyQuests[DAILY_QUEST_SIGN_IN].Transaction.Amount
-	if questCompleteRes.Transaction.Amount != expect {
-		t.Fatalf("unexpected transaction amount, expect: %v, receive: %v", expect, questCompleteRes.Transaction.Amount)
+	gems := 0
+	for _, reward := range quest.Rewards {
+		if reward.Type == TRANSACTION_GEMS {
+			gems += reward.Amount
+		}
 	}
 
 	// check resources in database
@@ -277,8 +567,8 @@ func TestDailyQuestComplete(t *testing.T) {
 	}
 
 	// expect gems in database to equal amount gained from completing the quest
-	if amount != questCompleteRes.Transaction.Amount {
-		t.Fatalf("unexpected amount in database, expect: %v, receive: %v", questCompleteRes.Transaction.Amount, amount)
+	if amount != gems {
+		t.Fatalf("unexpected amount in database, expect: %v, receive: %v", gems, amount)
 	}
 
 	// test already collected
@@ -300,116 +590,1357 @@ func TestDailyQuestComplete(t *testing.T) {
 	}
 }
 
-/* Summon Routes */
-
-func TestSummonUnit(t *testing.T) {
-	method := "PUT"
-	url := "/summon/unit"
-
+func TestDailyQuestProgressReset(t *testing.T) {
 	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	questId := DailyQuestsByObjective(idlemonServer.DataCache, DAILY_QUEST_COLLECT_CAMPAIGN)[0]
 
-	response := SendRequest(t, method, url, user.Id, token, nil)
-	body := ReadResponseBody(t, response)
+	// progress made yesterday
+	query := "UPDATE daily_quest_progress SET count = 2, progressed_at = $1 WHERE (user_id = $2 AND daily_quest_id = $3)"
+	yesterday := DailyQuestResetTime().Add(-time.Hour)
 
-	// should receive 400 for not enough gems
-	if response.StatusCode != http.StatusBadRequest {
-		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, yesterday, user.Id, questId); err != nil {
+		t.Fatalf("fail to update daily_quest_progress table: %v", err)
 	}
 
-	// give user some gems
-	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
-	_, err := idlemonServer.Db.Exec(context.Background(), query, UNIT_SUMMON_COST, user.Id, RESOURCE_GEMS)
+	// yesterday's progress should not be reported as today's
+	tx, err := idlemonServer.Db.Begin(context.Background())
 	if err != nil {
-		t.Fatalf("fail to update resources table: %v", err)
+		t.Fatalf("fail to begin transaction: %v", err)
 	}
 
-	// send request which should succeed
-	response = SendRequest(t, method, url, user.Id, token, nil)
-	body = ReadResponseBody(t, response)
+	progress, err := FindDailyQuestProgress(context.Background(), tx, user.Id, questId)
+	tx.Rollback(context.Background())
 
-	// should receive 200
-	if response.StatusCode != http.StatusOK {
-		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	if err != nil {
+		t.Fatalf("fail to find daily quest progress: %v", err)
 	}
 
-	// unmarshal success response
-	var summonUnitRes SummonUnitRes
-
-	if err := json.Unmarshal([]byte(body), &summonUnitRes); err != nil {
-		t.Fatalf("fail to unmarshal successful response: %v", err)
+	if progress.Count != 0 {
+		t.Fatalf("expect count 0 before progressing today, receive: %v", progress.Count)
 	}
 
-	// unit should exist in database
-	var template int
-
-	// fetch template of the summoned unit from the database
-	query = "SELECT template FROM units WHERE (id = $1 AND user_id = $2)"
-	err = idlemonServer.Db.QueryRow(context.Background(), query, summonUnitRes.Unit.Id, user.Id).Scan(&template)
-	if err != nil {
-		t.Errorf("fail to query units table: %v", err)
-	}
+	response := SendRequest(t, "PUT", "/campaign/collect", user.Id, token, nil)
+	body := ReadResponseBody(t, response)
 
-	if template != summonUnitRes.Unit.Template {
-		t.Errorf("unit template in database did not match response, expect: %v, receive: %v", summonUnitRes.Unit.Template, template)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
 	}
 
-	// resource table should be updated
-	var amount int
+	// the first progress of the day starts the count over
+	var count int
 
-	// query gems amount from the database
-	query = "SELECT amount FROM resources WHERE (user_id = $1 AND type = $2)"
-	_, err = idlemonServer.Db.Exec(context.Background(), query, user.Id, RESOURCE_GEMS)
-	if err != nil {
-		t.Fatalf("fail to query resources table: %v", err)
+	query = "SELECT count FROM daily_quest_progress WHERE (user_id = $1 AND daily_quest_id = $2)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, questId).Scan(&count); err != nil {
+		t.Fatalf("fail to query daily_quest_progress table: %v", err)
 	}
 
-	// amount of gems remaining should be 0
-	if amount != 0 {
-		t.Fatalf("expect amount in database to equal 0, receive: %v", amount)
+	if count != 1 {
+		t.Fatalf("expect count 1, receive: %v", count)
 	}
 }
 
-/* Unit Routes */
-
-func TestUnitLockRoute(t *testing.T) {
+func TestDailyQuestProgress(t *testing.T) {
 	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
-	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+	questId := DailyQuestsByObjective(idlemonServer.DataCache, DAILY_QUEST_SEND_CHAT)[0]
 
-	method := "PUT"
-	url := "/unit/" + unit.Id.String() + "/toggle-lock"
+	// remove the progress row as if the quest was added after the user signed up
+	query := "DELETE FROM daily_quest_progress WHERE (user_id = $1 AND daily_quest_id = $2)"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, user.Id, questId); err != nil {
+		t.Fatalf("fail to delete daily_quest_progress row: %v", err)
+	}
 
-	response := SendRequest(t, method, url, user.Id, token, nil)
+	response := SendRequest(t, "POST", "/chat/message/send", user.Id, token, &ChatMessageSendReq{Message: "hello"})
 	body := ReadResponseBody(t, response)
 
 	if response.StatusCode != http.StatusOK {
 		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
 	}
 
-	// unit must be locked in database
-	var isLocked bool
+	var count int
 
-	// query units table
-	query := "SELECT is_locked FROM units WHERE id = $1"
-	err := idlemonServer.Db.QueryRow(context.Background(), query, unit.Id).Scan(&isLocked)
-	if err != nil {
-		t.Fatalf("fail to query units table: %v", err)
+	query = "SELECT count FROM daily_quest_progress WHERE (user_id = $1 AND daily_quest_id = $2)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, questId).Scan(&count); err != nil {
+		t.Fatalf("fail to query daily_quest_progress table: %v", err)
 	}
 
-	if !isLocked {
-		t.Error("unit is not locked in the database")
+	if count != 1 {
+		t.Fatalf("expect count 1, receive: %v", count)
 	}
-}
 
-/* User Routes */
+	// the event should be recorded for analytics in the same transaction
+	query = "SELECT COUNT(*) FROM game_events WHERE (user_id = $1 AND name = $2)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, ChatSendEvent{}.Name()).Scan(&count); err != nil {
+		t.Fatalf("fail to query game_events table: %v", err)
+	}
 
-func TestUserSignUpRoute(t *testing.T) {
-	url := "/user/sign-up"
+	if count != 1 {
+		t.Fatalf("expect 1 chat send event, receive: %v", count)
+	}
+}
 
-	signUpReq := &SignUpReq{Name: "name", Email: "name@name.com", Pass: "password"}
-	response := PostRequest(t, url, signUpReq)
-	body := ReadResponseBody(t, response)
+/* Idle Upgrade Routes */
 
-	if response.StatusCode != http.StatusOK {
+func TestIdleUpgradeBuyRoute(t *testing.T) {
+	method := "PUT"
+	upgrade := idlemonServer.DataCache.IdleUpgrades[0]
+	url := fmt.Sprintf("/idle-upgrade/%v/buy", upgrade.Id)
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// not enough gold
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// give user enough gold for the first level
+	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, upgrade.Cost(0), user.Id, RESOURCE_GOLD); err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res IdleUpgradeBuyRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Upgrade.Level != 1 {
+		t.Fatalf("expect upgrade level 1, receive: %v", res.Upgrade.Level)
+	}
+
+	if res.Transaction.Amount != -upgrade.Cost(0) {
+		t.Fatalf("expect transaction amount %v, receive: %v", -upgrade.Cost(0), res.Transaction.Amount)
+	}
+
+	// level should be saved in the database
+	var level int
+
+	query = "SELECT level FROM idle_upgrades WHERE (user_id = $1 AND upgrade_id = $2)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, upgrade.Id).Scan(&level); err != nil {
+		t.Fatalf("fail to query idle_upgrades table: %v", err)
+	}
+
+	if level != 1 {
+		t.Fatalf("expect level in database to equal 1, receive: %v", level)
+	}
+}
+
+/* Item Routes */
+
+func TestItemRoutes(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+	template := idlemonServer.DataCache.Items[0]
+
+	var itemId int
+
+	query := "INSERT INTO items (user_id, template) VALUES ($1, $2) RETURNING id"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, template.Id).Scan(&itemId); err != nil {
+		t.Fatalf("fail to insert items row: %v", err)
+	}
+
+	url := fmt.Sprintf("/item/%v/equip", itemId)
+	response := SendRequest(t, "PUT", url, user.Id, token, &ItemEquipReq{UnitId: unit.Id})
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res ItemEquipRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	// item bonuses should be added to the unit stats
+	expect := CalcUnitStats(idlemonServer.DataCache, Unit{Template: unit.Template, Level: 1, Stars: 1})
+	expect.Atk += template.Atk
+
+	if res.Unit.Stats.Atk != expect.Atk {
+		t.Fatalf("expect atk %v, receive: %v", expect.Atk, res.Unit.Stats.Atk)
+	}
+
+	url = fmt.Sprintf("/item/%v/unequip", itemId)
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// the item is no longer equipped
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+/* Prestige Routes */
+
+func TestPrestigeRoute(t *testing.T) {
+	method := "PUT"
+	url := "/prestige"
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// campaign level is too low
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// set campaign level and give the user some gold
+	query := "UPDATE campaign SET level = $1 WHERE user_id = $2"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, PRESTIGE_MIN_LEVEL, user.Id); err != nil {
+		t.Fatalf("fail to update campaign table: %v", err)
+	}
+
+	query = "UPDATE resources SET amount = 1000 WHERE (user_id = $1 AND type = $2)"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, user.Id, RESOURCE_GOLD); err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res PrestigeRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Prestige.Count != 1 {
+		t.Fatalf("expect prestige count 1, receive: %v", res.Prestige.Count)
+	}
+
+	// campaign level and gold should be reset
+	var level, gold int
+
+	query = "SELECT level FROM campaign WHERE user_id = $1"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id).Scan(&level); err != nil {
+		t.Fatalf("fail to query campaign table: %v", err)
+	}
+
+	if level != 1 {
+		t.Fatalf("expect campaign level 1, receive: %v", level)
+	}
+
+	query = "SELECT amount FROM resources WHERE (user_id = $1 AND type = $2)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, RESOURCE_GOLD).Scan(&gold); err != nil {
+		t.Fatalf("fail to query resources table: %v", err)
+	}
+
+	if gold != 0 {
+		t.Fatalf("expect gold to be reset to 0, receive: %v", gold)
+	}
+}
+
+func TestPrestigeMissingRowRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// users who signed up before prestige existed have no prestige row
+	if _, err := idlemonServer.Db.Exec(context.Background(), "DELETE FROM prestige WHERE user_id = $1", user.Id); err != nil {
+		t.Fatalf("fail to delete prestige row: %v", err)
+	}
+
+	response := SendRequest(t, "PUT", "/campaign/collect", user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	query := "UPDATE campaign SET level = $1 WHERE user_id = $2"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, PRESTIGE_MIN_LEVEL, user.Id); err != nil {
+		t.Fatalf("fail to update campaign table: %v", err)
+	}
+
+	response = SendRequest(t, "PUT", "/prestige", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res PrestigeRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Prestige.Count != 1 {
+		t.Fatalf("expect prestige count 1, receive: %v", res.Prestige.Count)
+	}
+}
+
+func TestPrestigeKeepsClaimedChestsRoute(t *testing.T) {
+	url := "/campaign/chapter/1/chest/0"
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	earnChapterStars := func() {
+		for stage := 1; stage <= CAMPAIGN_CHAPTER_STAGES; stage++ {
+			query := "INSERT INTO campaign_stages (user_id, stage, stars) VALUES ($1, $2, $3)"
+			if _, err := idlemonServer.Db.Exec(context.Background(), query, user.Id, stage, CAMPAIGN_MAX_STARS); err != nil {
+				t.Fatalf("fail to insert campaign_stages row: %v", err)
+			}
+		}
+	}
+
+	earnChapterStars()
+
+	response := SendRequest(t, "PUT", url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	query := "UPDATE campaign SET level = $1 WHERE user_id = $2"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, PRESTIGE_MIN_LEVEL, user.Id); err != nil {
+		t.Fatalf("fail to update campaign table: %v", err)
+	}
+
+	response = SendRequest(t, "PUT", "/prestige", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// the chapter stars are earned again after prestiging, but the chest was already claimed
+	earnChapterStars()
+
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+/* Roster Routes */
+
+func TestRosterExpandRoute(t *testing.T) {
+	method := "PUT"
+	url := "/roster/expand"
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	config := idlemonServer.DataCache.Roster
+
+	// not enough gems
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, config.ExpansionCost, user.Id, RESOURCE_GEMS); err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res RosterExpandRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if expect := config.DefaultCapacity + config.ExpansionSize; res.Roster.Capacity != expect {
+		t.Fatalf("expect capacity %v, receive: %v", expect, res.Roster.Capacity)
+	}
+
+	// shrink the roster to 0 capacity, summons should be rejected with the roster full error code
+	query = "UPDATE users SET unit_expansions = $1 WHERE id = $2"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, -config.DefaultCapacity/config.ExpansionSize, user.Id); err != nil {
+		t.Fatalf("fail to update users table: %v", err)
+	}
+
+	response = SendRequest(t, method, "/summon/unit", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	var errRes ErrorResponse
+
+	if err := json.Unmarshal([]byte(body), &errRes); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if response.StatusCode != http.StatusBadRequest || errRes.Code != ERR_CODE_ROSTER_FULL {
+		t.Fatalf("expect roster full error, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+/* Summon Routes */
+
+func TestSummonHistoryRoutes(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// give user enough gems for a multi summon
+	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
+	_, err := idlemonServer.Db.Exec(context.Background(), query, idlemonServer.DataCache.MultiSummon.Cost(), user.Id, RESOURCE_GEMS)
+	if err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	response := SendRequest(t, "PUT", "/summon/multi", user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// every summoned unit should be in the history
+	response = SendRequest(t, "GET", "/summon/history", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var summonHistoryRes SummonHistoryRes
+
+	if err := json.Unmarshal([]byte(body), &summonHistoryRes); err != nil {
+		t.Fatalf("fail to unmarshal response: %v", err)
+	}
+
+	if len(summonHistoryRes.Records) != idlemonServer.DataCache.MultiSummon.Count {
+		t.Fatalf("expect %v records, receive: %v", idlemonServer.DataCache.MultiSummon.Count, len(summonHistoryRes.Records))
+	}
+
+	// the costs of the records should add up to the multi summon cost
+	cost := 0
+	for _, record := range summonHistoryRes.Records {
+		cost += record.Cost
+	}
+
+	if cost != idlemonServer.DataCache.MultiSummon.Cost() {
+		t.Errorf("expect total cost %v, receive: %v", idlemonServer.DataCache.MultiSummon.Cost(), cost)
+	}
+
+	// should receive 400 for an invalid cursor
+	response = SendRequest(t, "GET", "/summon/history?cursor=abc", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// the report is only available to the admin user
+	response = SendRequest(t, "GET", "/summon/report", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusForbidden {
+		t.Fatalf("expect status 403, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+func TestSummonReportRoute(t *testing.T) {
+	method := "GET"
+	url := "/summon/report"
+	rates := EffectiveSummonRates(idlemonServer.DataCache)
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// the admin flag can only be set by the admin insert, set it directly for the test
+	query := "UPDATE users SET is_admin = true WHERE id = $1"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, user.Id); err != nil {
+		t.Fatalf("fail to update users table: %v", err)
+	}
+
+	// the report covers every user, replace the summons from other tests with known rows
+	if _, err := idlemonServer.Db.Exec(context.Background(), "DELETE FROM summon_history"); err != nil {
+		t.Fatalf("fail to delete summon_history rows: %v", err)
+	}
+
+	counts := []int{3, 1}
+	for i, count := range counts {
+		for j := 0; j < count; j++ {
+			query := `INSERT INTO summon_history (user_id, banner_id, template, rarity, cost_type, cost, seed, created_at)
+					  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+			_, err := idlemonServer.Db.Exec(context.Background(), query, user.Id, SUMMON_STANDARD_BANNER, 1, rates[i].Rarity,
+				TRANSACTION_GEMS, 0, 0, time.Now())
+			if err != nil {
+				t.Fatalf("fail to insert summon_history row: %v", err)
+			}
+		}
+	}
+
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res SummonReportRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Total != 4 {
+		t.Fatalf("expect total 4, receive: %v", res.Total)
+	}
+
+	if len(res.Rates) != len(rates) {
+		t.Fatalf("expect %v rates, receive: %v", len(rates), len(res.Rates))
+	}
+
+	observed := []int{7500, 2500}
+
+	for i, report := range res.Rates {
+		count, observedRate := 0, 0
+		if i < len(counts) {
+			count, observedRate = counts[i], observed[i]
+		}
+
+		if report.Rarity != rates[i].Rarity {
+			t.Errorf("expect rarity %v, receive: %v", rates[i].Rarity, report.Rarity)
+		}
+
+		if report.ConfiguredRate != rates[i].Rate {
+			t.Errorf("expect rarity %v configured rate %v, receive: %v", report.Rarity, rates[i].Rate, report.ConfiguredRate)
+		}
+
+		if report.Count != count {
+			t.Errorf("expect rarity %v count %v, receive: %v", report.Rarity, count, report.Count)
+		}
+
+		if report.ObservedRate != observedRate {
+			t.Errorf("expect rarity %v observed rate %v, receive: %v", report.Rarity, observedRate, report.ObservedRate)
+		}
+	}
+}
+
+func TestSummonMultiRoute(t *testing.T) {
+	method := "PUT"
+	url := "/summon/multi"
+	multi := idlemonServer.DataCache.MultiSummon
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// should receive 400 for not enough gems
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// give user exactly enough gems
+	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
+	_, err := idlemonServer.Db.Exec(context.Background(), query, multi.Cost(), user.Id, RESOURCE_GEMS)
+	if err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var summonMultiRes SummonMultiRes
+
+	if err := json.Unmarshal([]byte(body), &summonMultiRes); err != nil {
+		t.Fatalf("fail to unmarshal successful response: %v", err)
+	}
+
+	if len(summonMultiRes.Units) != multi.Count {
+		t.Fatalf("expect %v units, receive: %v", multi.Count, len(summonMultiRes.Units))
+	}
+
+	if summonMultiRes.Transaction.Amount != -multi.Cost() {
+		t.Errorf("expect transaction amount %v, receive: %v", -multi.Cost(), summonMultiRes.Transaction.Amount)
+	}
+
+	// at least one unit should have the guaranteed rarity
+	guaranteed := false
+	for _, unit := range summonMultiRes.Units {
+		template, _ := FindUnitTemplate(idlemonServer.DataCache, unit.Template)
+		if template.Rarity >= multi.GuaranteedRarity {
+			guaranteed = true
+		}
+	}
+
+	if !guaranteed {
+		t.Errorf("expect a unit with rarity %v or higher, receive: %+v", multi.GuaranteedRarity, summonMultiRes.Units)
+	}
+
+	// every unit should be inserted
+	var count int
+
+	query = "SELECT COUNT(*) FROM units WHERE user_id = $1"
+	err = idlemonServer.Db.QueryRow(context.Background(), query, user.Id).Scan(&count)
+	if err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if count != multi.Count {
+		t.Errorf("expect %v units in database, receive: %v", multi.Count, count)
+	}
+}
+
+func TestSummonRatesRoute(t *testing.T) {
+	response := GetRequest(t, "/summon/rates")
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res SummonRatesRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	// every template should be listed and the rates should add up to about 100%
+	total, templates := 0, 0
+
+	for _, rarity := range res.Rates {
+		total += rarity.Rate
+		templates += len(rarity.Templates)
+	}
+
+	if total < 9900 || total > 10000 {
+		t.Fatalf("expect rates to add up to 10000, receive: %v", total)
+	}
+
+	if templates != len(idlemonServer.DataCache.UnitTemplates) {
+		t.Fatalf("expect %v templates, receive: %v", len(idlemonServer.DataCache.UnitTemplates), templates)
+	}
+}
+
+func TestSummonUnit(t *testing.T) {
+	method := "PUT"
+	url := "/summon/unit"
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	// should receive 400 for not enough gems
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// banners that don't exist can't be summoned on
+	response = SendRequest(t, method, url+"?banner=0", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// give user some gems
+	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
+	_, err := idlemonServer.Db.Exec(context.Background(), query, UNIT_SUMMON_COST, user.Id, RESOURCE_GEMS)
+	if err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	// send request which should succeed
+	response = SendRequest(t, method, url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	// should receive 200
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// unmarshal success response
+	var summonUnitRes SummonUnitRes
+
+	if err := json.Unmarshal([]byte(body), &summonUnitRes); err != nil {
+		t.Fatalf("fail to unmarshal successful response: %v", err)
+	}
+
+	// the standard banner should be used by default
+	if summonUnitRes.Pity.BannerId != SUMMON_STANDARD_BANNER {
+		t.Errorf("expect pity for banner %v, receive: %+v", SUMMON_STANDARD_BANNER, summonUnitRes.Pity)
+	}
+
+	// unit should exist in database
+	var template int
+
+	// fetch template of the summoned unit from the database
+	query = "SELECT template FROM units WHERE (id = $1 AND user_id = $2)"
+	err = idlemonServer.Db.QueryRow(context.Background(), query, summonUnitRes.Unit.Id, user.Id).Scan(&template)
+	if err != nil {
+		t.Errorf("fail to query units table: %v", err)
+	}
+
+	if template != summonUnitRes.Unit.Template {
+		t.Errorf("unit template in database did not match response, expect: %v, receive: %v", summonUnitRes.Unit.Template, template)
+	}
+
+	// resource table should be updated
+	var amount int
+
+	// query gems amount from the database
+	query = "SELECT amount FROM resources WHERE (user_id = $1 AND type = $2)"
+	err = idlemonServer.Db.QueryRow(context.Background(), query, user.Id, RESOURCE_GEMS).Scan(&amount)
+	if err != nil {
+		t.Fatalf("fail to query resources table: %v", err)
+	}
+
+	// only the codex discovery reward should remain
+	if amount != CODEX_DISCOVERY_REWARD {
+		t.Fatalf("expect amount in database to equal %v, receive: %v", CODEX_DISCOVERY_REWARD, amount)
+	}
+
+	// the summoned template should be discovered
+	response = SendRequest(t, "GET", "/codex", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var codexRes CodexRes
+
+	if err := json.Unmarshal([]byte(body), &codexRes); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if len(codexRes.Codex) != len(idlemonServer.DataCache.UnitTemplates) {
+		t.Fatalf("expect %v codex entries, receive: %v", len(idlemonServer.DataCache.UnitTemplates), len(codexRes.Codex))
+	}
+
+	for _, entry := range codexRes.Codex {
+		if entry.IsDiscovered != (entry.Template == summonUnitRes.Unit.Template) {
+			t.Fatalf("unexpected codex entry: %+v", entry)
+		}
+	}
+}
+
+func TestSummonUnitCurrencies(t *testing.T) {
+	method := "PUT"
+
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	// should receive 400 for an invalid currency
+	response := SendRequest(t, method, "/summon/unit?currency=gold", user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// should receive 400 for not enough summon tickets
+	response = SendRequest(t, method, "/summon/unit?currency=tickets", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// the free summon is available right after sign up
+	response = SendRequest(t, method, "/summon/unit?currency=free", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var summonUnitRes SummonUnitRes
+
+	if err := json.Unmarshal([]byte(body), &summonUnitRes); err != nil {
+		t.Fatalf("fail to unmarshal successful response: %v", err)
+	}
+
+	if !summonUnitRes.NextFreeSummonAt.After(time.Now()) {
+		t.Errorf("expect next free summon to be in the future, receive: %v", summonUnitRes.NextFreeSummonAt)
+	}
+
+	// the free summon is recorded with its own cost type
+	var costType, cost int
+
+	query := "SELECT cost_type, cost FROM summon_history WHERE user_id = $1 ORDER BY id DESC LIMIT 1"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id).Scan(&costType, &cost); err != nil {
+		t.Fatalf("fail to query summon_history table: %v", err)
+	}
+
+	if costType != TRANSACTION_FREE_SUMMON || cost != 0 {
+		t.Errorf("expect cost type %v and cost 0, receive: %v and %v", TRANSACTION_FREE_SUMMON, costType, cost)
+	}
+
+	// the free summon can't be used twice in a row
+	response = SendRequest(t, method, "/summon/unit?currency=free", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// grant a summon ticket
+	tx, err := idlemonServer.Db.Begin(context.Background())
+	if err != nil {
+		t.Fatalf("fail to begin transaction: %v", err)
+	}
+	defer tx.Rollback(context.Background())
+
+	ticket := Transaction{Type: TRANSACTION_SUMMON_TICKETS, Amount: 1}
+
+	if err := ticket.Apply(context.Background(), tx, user.Id); err != nil {
+		t.Fatalf("fail to apply transaction: %v", err)
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		t.Fatalf("fail to commit transaction: %v", err)
+	}
+
+	response = SendRequest(t, method, "/summon/unit?currency=tickets", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	if err := json.Unmarshal([]byte(body), &summonUnitRes); err != nil {
+		t.Fatalf("fail to unmarshal successful response: %v", err)
+	}
+
+	if summonUnitRes.Transaction.Type != TRANSACTION_SUMMON_TICKETS || summonUnitRes.Transaction.Amount != -1 {
+		t.Errorf("expect a transaction of -1 summon tickets, receive: %+v", summonUnitRes.Transaction)
+	}
+}
+
+/* Team Routes */
+
+func TestTeamRoutes(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	_, otherUser := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+	otherUnit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, otherUser.Id)
+
+	// units owned by another user cannot be added to a team
+	request := &TeamReq{Name: "Alpha", Units: []TeamSlot{{Slot: 0, UnitId: otherUnit.Id}}}
+	response := SendRequest(t, "POST", "/team", user.Id, token, request)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	request.Units = []TeamSlot{{Slot: 2, UnitId: unit.Id}}
+	response = SendRequest(t, "POST", "/team", user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res TeamRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	url := fmt.Sprintf("/team/%v/campaign-default", res.Team.Id)
+	response = SendRequest(t, "PUT", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	response = SendRequest(t, "GET", "/teams", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var teamsRes TeamsRes
+
+	if err := json.Unmarshal([]byte(body), &teamsRes); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if len(teamsRes.Teams) != 1 || !teamsRes.Teams[0].IsCampaignDefault || len(teamsRes.Teams[0].Units) != 1 {
+		t.Fatalf("unexpected teams: %+v", teamsRes.Teams)
+	}
+
+	// units in a team cannot be retired
+	retireReq := &UnitRetireReq{UnitIds: []uuid.UUID{unit.Id}}
+	response = SendRequest(t, "PUT", "/units/retire", user.Id, token, retireReq)
+	body = ReadResponseBody(t, response)
+
+	var retireRes UnitRetireRes
+
+	if err := json.Unmarshal([]byte(body), &retireRes); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if len(retireRes.Results) != 1 || retireRes.Results[0].Success {
+		t.Fatalf("expect team unit to not be retired, receive: %v", body)
+	}
+
+	url = fmt.Sprintf("/team/%v", res.Team.Id)
+	response = SendRequest(t, "DELETE", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// the team is already deleted
+	response = SendRequest(t, "DELETE", url, user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect status 404, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+/* Unit Routes */
+
+func TestUnitListRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	for level := 1; level <= 3; level++ {
+		unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+
+		query := "UPDATE units SET level = $1, is_locked = $2 WHERE id = $3"
+		if _, err := idlemonServer.Db.Exec(context.Background(), query, level, level == 2, unit.Id); err != nil {
+			t.Fatalf("fail to update units table: %v", err)
+		}
+	}
+
+	// units should be sorted by level
+	response := SendRequest(t, "GET", "/units?sort=level", user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res UnitsRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if len(res.Units) != 3 || res.NextCursor != "" {
+		t.Fatalf("expect 3 units and no next cursor, receive: %+v", res)
+	}
+
+	for i, unit := range res.Units {
+		if unit.Level != 3-i {
+			t.Fatalf("expect unit %v to have level %v, receive: %v", i, 3-i, unit.Level)
+		}
+	}
+
+	// filter locked units
+	response = SendRequest(t, "GET", "/units?locked=true", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if len(res.Units) != 1 || !res.Units[0].IsLocked {
+		t.Fatalf("expect 1 locked unit, receive: %+v", res.Units)
+	}
+
+	// invalid sort option
+	response = SendRequest(t, "GET", "/units?sort=name", user.Id, token, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+func TestUnitDetailRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+
+	response := SendRequest(t, "GET", "/unit/"+unit.Id.String(), user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res UnitRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Unit.Id != unit.Id {
+		t.Fatalf("expect unit ID %v, receive: %v", unit.Id, res.Unit.Id)
+	}
+
+	// stats should be calculated from the template's base stats
+	expect := CalcUnitStats(idlemonServer.DataCache, unit)
+	if res.Unit.Stats != expect {
+		t.Fatalf("invalid unit stats, expect: %+v, receive: %+v", expect, res.Unit.Stats)
+	}
+
+	// units of other users are not found
+	token2, user2 := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	response = SendRequest(t, "GET", "/unit/"+unit.Id.String(), user2.Id, token2, nil)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect status 404, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+func TestUnitLockRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+
+	method := "PUT"
+	url := "/unit/" + unit.Id.String() + "/toggle-lock"
+
+	response := SendRequest(t, method, url, user.Id, token, nil)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// unit must be locked in database
+	var isLocked bool
+
+	// query units table
+	query := "SELECT is_locked FROM units WHERE id = $1"
+	err := idlemonServer.Db.QueryRow(context.Background(), query, unit.Id).Scan(&isLocked)
+	if err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if !isLocked {
+		t.Error("unit is not locked in the database")
+	}
+}
+
+func TestUnitEvolveRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	template := idlemonServer.DataCache.UnitTemplates[0].ID
+	tier, _ := idlemonServer.DataCache.UnitProgression.EvolveTier(1)
+
+	unit := InsertTemplateUnit(t, idlemonServer.Db, user.Id, template)
+
+	fodderIds := make([]uuid.UUID, tier.FodderCount)
+	for i := range fodderIds {
+		fodderIds[i] = InsertTemplateUnit(t, idlemonServer.Db, user.Id, template).Id
+	}
+
+	method := "PUT"
+	url := "/unit/" + unit.Id.String() + "/evolve"
+	request := &UnitEvolveReq{FodderIds: fodderIds}
+
+	// give the user enough evo stones
+	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, tier.EvoStones, user.Id, RESOURCE_EVO_STONE); err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	// a unit away on a bounty cannot be evolved
+	var bountyId int
+
+	query = `INSERT INTO bounties (user_id, board_date, duration, unit_type, min_stars, unit_count, reward_type, reward_amount, started_at)
+			 VALUES ($1, $2, 3600, 0, 1, 1, $3, 1, $2) RETURNING id`
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, time.Now(), TRANSACTION_GOLD).Scan(&bountyId); err != nil {
+		t.Fatalf("fail to insert bounties row: %v", err)
+	}
+
+	query = "UPDATE units SET bounty_id = $1 WHERE id = $2"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, bountyId, unit.Id); err != nil {
+		t.Fatalf("fail to update units table: %v", err)
+	}
+
+	response := SendRequest(t, method, url, user.Id, token, request)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	query = "UPDATE units SET bounty_id = NULL WHERE id = $1"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, unit.Id); err != nil {
+		t.Fatalf("fail to update units table: %v", err)
+	}
+
+	// locked units cannot be fodder
+	query = "UPDATE units SET is_locked = TRUE WHERE id = $1"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, fodderIds[0]); err != nil {
+		t.Fatalf("fail to update units table: %v", err)
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	query = "UPDATE units SET is_locked = FALSE WHERE id = $1"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, fodderIds[0]); err != nil {
+		t.Fatalf("fail to update units table: %v", err)
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res UnitEvolveRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Unit.Stars != 2 {
+		t.Fatalf("expect unit stars to equal 2, receive: %v", res.Unit.Stars)
+	}
+
+	// fodder units should be deleted
+	var count int
+
+	query = "SELECT COUNT(*) FROM units WHERE id = ANY($1)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, fodderIds).Scan(&count); err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expect fodder units to be deleted, %v remain", count)
+	}
+}
+
+func TestUnitLevelUpRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+
+	method := "PUT"
+	url := "/unit/" + unit.Id.String() + "/level-up"
+	request := &UnitLevelUpReq{Levels: 3}
+
+	// not enough resources
+	response := SendRequest(t, method, url, user.Id, token, request)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// give the user exactly enough resources
+	gold, expStones := idlemonServer.DataCache.UnitProgression.LevelUpCost(1, request.Levels)
+
+	query := "UPDATE resources SET amount = $1 WHERE (user_id = $2 AND type = $3)"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, gold, user.Id, RESOURCE_GOLD); err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, expStones, user.Id, RESOURCE_EXP_STONE); err != nil {
+		t.Fatalf("fail to update resources table: %v", err)
+	}
+
+	response = SendRequest(t, method, url, user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res UnitLevelUpRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	if res.Unit.Level != 1+request.Levels {
+		t.Fatalf("expect unit level %v, receive: %v", 1+request.Levels, res.Unit.Level)
+	}
+
+	// level should be saved and resources spent
+	var level, amount int
+
+	query = "SELECT level FROM units WHERE id = $1"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, unit.Id).Scan(&level); err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if level != res.Unit.Level {
+		t.Fatalf("expect level in database to equal %v, receive: %v", res.Unit.Level, level)
+	}
+
+	query = "SELECT amount FROM resources WHERE (user_id = $1 AND type = $2)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, RESOURCE_GOLD).Scan(&amount); err != nil {
+		t.Fatalf("fail to query resources table: %v", err)
+	}
+
+	if amount != 0 {
+		t.Fatalf("expect gold to equal 0, receive: %v", amount)
+	}
+
+	// level cap cannot be exceeded
+	request.Levels = UNIT_MAX_LEVEL_UPS
+	response = SendRequest(t, method, url, user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+}
+
+func TestUnitBulkLockRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	_, otherUser := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+
+	unitIds := make([]uuid.UUID, 3)
+	for i := range unitIds {
+		unitIds[i] = InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id).Id
+	}
+
+	otherUnit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, otherUser.Id)
+
+	// the batch is rejected if a unit is not owned
+	request := &UnitBulkLockReq{UnitIds: append([]uuid.UUID{otherUnit.Id}, unitIds...), Locked: true}
+	response := SendRequest(t, "PUT", "/units/lock", user.Id, token, request)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	request.UnitIds = unitIds
+	response = SendRequest(t, "PUT", "/units/lock", user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var count int
+
+	query := "SELECT COUNT(*) FROM units WHERE (id = ANY($1) AND is_locked)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, unitIds).Scan(&count); err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if count != len(unitIds) {
+		t.Fatalf("expect %v locked units, receive: %v", len(unitIds), count)
+	}
+}
+
+func TestUnitRetireRoute(t *testing.T) {
+	token, user := AuthenticatedUser(t, idlemonServer.Db, idlemonServer.Rdb, idlemonServer.DataCache)
+	unit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+	lockedUnit := InsertRandUnit(t, idlemonServer.Db, idlemonServer.DataCache, user.Id)
+
+	method := "PUT"
+	url := "/units/retire"
+
+	query := "UPDATE units SET is_locked = TRUE WHERE id = $1"
+	if _, err := idlemonServer.Db.Exec(context.Background(), query, lockedUnit.Id); err != nil {
+		t.Fatalf("fail to update units table: %v", err)
+	}
+
+	// the batch is rejected if a unit is not owned
+	request := &UnitRetireReq{UnitIds: []uuid.UUID{unit.Id, uuid.New()}}
+	response := SendRequest(t, method, url, user.Id, token, request)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	// locked units are skipped
+	request.UnitIds = []uuid.UUID{unit.Id, lockedUnit.Id}
+	response = SendRequest(t, method, url, user.Id, token, request)
+	body = ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, received: %v, body: %v", response.StatusCode, body)
+	}
+
+	var res UnitRetireRes
+
+	if err := json.Unmarshal([]byte(body), &res); err != nil {
+		t.Fatalf("fail to unmarshal response body: %v", err)
+	}
+
+	for _, result := range res.Results {
+		if result.Success != (result.UnitId == unit.Id) {
+			t.Fatalf("unexpected retire result: %+v", result)
+		}
+	}
+
+	// a 1 star unit refunds evo stones
+	var evoStones int
+
+	query = "SELECT amount FROM resources WHERE (user_id = $1 AND type = $2)"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, user.Id, RESOURCE_EVO_STONE).Scan(&evoStones); err != nil {
+		t.Fatalf("fail to query resources table: %v", err)
+	}
+
+	if expect := idlemonServer.DataCache.UnitProgression.Retire.EvoStonesPerStar; evoStones != expect {
+		t.Fatalf("expect evo stones to equal %v, receive: %v", expect, evoStones)
+	}
+
+	// unit should be deleted
+	var count int
+
+	query = "SELECT COUNT(*) FROM units WHERE id = $1"
+	if err := idlemonServer.Db.QueryRow(context.Background(), query, unit.Id).Scan(&count); err != nil {
+		t.Fatalf("fail to query units table: %v", err)
+	}
+
+	if count != 0 {
+		t.Fatal("retired unit was not deleted")
+	}
+}
+
+/* User Routes */
+
+func TestUserSignUpRoute(t *testing.T) {
+	url := "/user/sign-up"
+
+	signUpReq := &SignUpReq{Name: "name", Email: "name@name.com", Pass: "password"}
+	response := PostRequest(t, url, signUpReq)
+	body := ReadResponseBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
 		t.Errorf("expect status 200, received: %v, body: %v", response.StatusCode, body)
 	}
 
@@ -504,6 +2035,11 @@ func TestUserSignInRoute(t *testing.T) {
 		t.Fatalf("response should have no password, received: %v", signInRes.User.Pass)
 	}
 
+	// type advantages should be sent to the client
+	if len(signInRes.TypeAdvantageData.Advantages) != len(idlemonServer.DataCache.TypeAdvantage.Advantages) {
+		t.Fatalf("expect type advantages: %v, receive: %v", idlemonServer.DataCache.TypeAdvantage, signInRes.TypeAdvantageData)
+	}
+
 	// api token should exist
 	tokenResult, err := idlemonServer.Rdb.Get(context.Background(), user.Id.String()).Result()
 	if err != nil {
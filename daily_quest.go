@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -10,28 +11,48 @@ import (
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
-// Daily quest has a required number to complete it and a transaction representing the reward given for completion.
+// Daily quest is completed by performing its objective the required number of times. The rewards are
+// given for completion.
 type DailyQuest struct {
-	Id          int
-	Required    int
-	Transaction Transaction
+	Id        int           `json:"id"`
+	Objective int           `json:"objective"`
+	Required  int           `json:"required"`
+	Rewards   []Transaction `json:"rewards"`
 }
 
-// Returns a slice of all the daily quests
-func DailyQuests() []DailyQuest {
-	dailyQuests := make([]DailyQuest, 1)
-
-	// require user to sign in
-	dailyQuests[DAILY_QUEST_SIGN_IN] = DailyQuest{
-		Id:       DAILY_QUEST_SIGN_IN,
-		Required: 1,
-		Transaction: Transaction{
-			Type:   TRANSACTION_GEMS,
-			Amount: 1000,
-		},
+func UnMarshalDailyQuestsJson() ([]DailyQuest, error) {
+	var data map[string][]DailyQuest
+
+	err := json.Unmarshal([]byte(dailyQuestsJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["dailyQuests"], nil
+}
+
+// Find the daily quest with the given ID. The bool will be false if the quest doesn't exist.
+func FindDailyQuest(dc *DataCache, id int) (DailyQuest, bool) {
+	for _, quest := range dc.DailyQuests {
+		if quest.Id == id {
+			return quest, true
+		}
+	}
+
+	return DailyQuest{}, false
+}
+
+// Returns the IDs of the daily quests with the given objective.
+func DailyQuestsByObjective(dc *DataCache, objective int) []int {
+	ids := make([]int, 0)
+
+	for _, quest := range dc.DailyQuests {
+		if quest.Objective == objective {
+			ids = append(ids, quest.Id)
+		}
 	}
 
-	return dailyQuests
+	return ids
 }
 
 type DailyQuestProgress struct {
@@ -40,6 +61,7 @@ type DailyQuestProgress struct {
 	DailyQuestId    int       `json:"dailyQuestId"`
 	Count           int       `json:"count"`
 	LastCompletedAt time.Time `json:"lastCompletedAt"`
+	ProgressedAt    time.Time `json:"-"` // The last time the count was increased.
 }
 
 func CreateDailyQuestProgress() DailyQuestProgress {
@@ -49,24 +71,77 @@ func CreateDailyQuestProgress() DailyQuestProgress {
 	return DailyQuestProgress{LastCompletedAt: lastCompletedAt}
 }
 
-// Will check if the quest has already been completed today.
-func (dqp *DailyQuestProgress) IsCompleted() bool {
+// Returns the start of the current day, quests completed after it can't be completed again today.
+func DailyQuestResetTime() time.Time {
 	now := time.Now()
 	y, m, d := now.Date()
-	startOfToday := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
 
-	return dqp.LastCompletedAt.Unix() >= startOfToday.Unix()
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location())
 }
 
-// Will increase the count if the quest hasn't been completed today.
-func (dqp *DailyQuestProgress) IncreaseCount(ctx context.Context, tx pgx.Tx) error {
-	if !dqp.IsCompleted() {
-		_, err := tx.Exec(ctx, "UPDATE daily_quest_progress SET count = count + 1 WHERE id = $1", dqp.Id)
-		if err != nil {
-			return fmt.Errorf("fail to update daily_quest_progress table: %w", err)
+// Progress made before the start of the current day doesn't count towards today's quest.
+func (dqp *DailyQuestProgress) ClearStaleCount() {
+	if dqp.ProgressedAt.Before(DailyQuestResetTime()) {
+		dqp.Count = 0
+	}
+}
+
+// Will check if the quest has already been completed today.
+func (dqp *DailyQuestProgress) IsCompleted() bool {
+	return dqp.LastCompletedAt.Unix() >= DailyQuestResetTime().Unix()
+}
+
+// Will subscribe to the events that progress each daily quest objective.
+func SubscribeDailyQuests(bus *EventBus, dc *DataCache) {
+	progress := func(objective int, amount func(event Event) int) EventHandler {
+		return func(ctx context.Context, tx pgx.Tx, event Event) error {
+			if n := amount(event); n > 0 {
+				return IncDailyQuestProgress(ctx, tx, dc, event.User(), objective, n)
+			}
+
+			return nil
+		}
+	}
+
+	once := func(event Event) int { return 1 }
+
+	bus.Subscribe(SignInEvent{}, progress(DAILY_QUEST_SIGN_IN, once))
+	bus.Subscribe(CampaignCollectEvent{}, progress(DAILY_QUEST_COLLECT_CAMPAIGN, once))
+	bus.Subscribe(ChatSendEvent{}, progress(DAILY_QUEST_SEND_CHAT, once))
+
+	bus.Subscribe(CampaignBattleEvent{}, progress(DAILY_QUEST_WIN_BATTLES, func(event Event) int {
+		if event.(CampaignBattleEvent).Win {
+			return 1
 		}
+		return 0
+	}))
+
+	bus.Subscribe(UnitSummonEvent{}, progress(DAILY_QUEST_SUMMON_UNITS, func(event Event) int {
+		return len(event.(UnitSummonEvent).Templates)
+	}))
 
-		dqp.Count++
+	bus.Subscribe(UnitLevelUpEvent{}, progress(DAILY_QUEST_LEVEL_UP_UNITS, func(event Event) int {
+		return event.(UnitLevelUpEvent).Levels
+	}))
+}
+
+// Will increase the count of every daily quest with the objective that hasn't been completed today.
+// Progress rows are inserted for quests that were added after the user signed up.
+func IncDailyQuestProgress(ctx context.Context, tx pgx.Tx, dc *DataCache, userId uuid.UUID, objective int, amount int) error {
+	for _, questId := range DailyQuestsByObjective(dc, objective) {
+		progress := CreateDailyQuestProgress()
+
+		// the count starts over on the first progress of a new day
+		query := `INSERT INTO daily_quest_progress (user_id, daily_quest_id, count, last_completed_at, progressed_at)
+				  VALUES ($1, $2, $3, $4, $6)
+				  ON CONFLICT (user_id, daily_quest_id) DO UPDATE SET progressed_at = $6, count = CASE
+				  WHEN daily_quest_progress.progressed_at < $5 THEN $3 ELSE daily_quest_progress.count + $3 END
+				  WHERE daily_quest_progress.last_completed_at < $5`
+
+		_, err := tx.Exec(ctx, query, userId, questId, amount, progress.LastCompletedAt, DailyQuestResetTime(), time.Now())
+		if err != nil {
+			return fmt.Errorf("fail to upsert daily_quest_progress row: %w", err)
+		}
 	}
 
 	return nil
@@ -91,7 +166,7 @@ func (dqp *DailyQuestProgress) Complete(ctx context.Context, tx pgx.Tx) error {
 func FindAllDailyQuestProgress(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) ([]DailyQuestProgress, error) {
 	dailyQuestProgress := make([]DailyQuestProgress, 0)
 
-	query := "SELECT id, daily_quest_id, count, last_completed_at FROM daily_quest_progress WHERE user_id = $1"
+	query := "SELECT id, daily_quest_id, count, last_completed_at, progressed_at FROM daily_quest_progress WHERE user_id = $1"
 	rows, err := db.Query(ctx, query, userId)
 	if err != nil {
 		return dailyQuestProgress, fmt.Errorf("faily to query daily_quest_progress table: %w", err)
@@ -100,34 +175,42 @@ func FindAllDailyQuestProgress(ctx context.Context, db *pgxpool.Pool, userId uui
 	for rows.Next() {
 		var progress DailyQuestProgress
 
-		err := rows.Scan(&progress.Id, &progress.DailyQuestId, &progress.Count, &progress.LastCompletedAt)
+		err := rows.Scan(&progress.Id, &progress.DailyQuestId, &progress.Count, &progress.LastCompletedAt, &progress.ProgressedAt)
 		if err != nil {
 			return dailyQuestProgress, fmt.Errorf("fail to scan rows: %w", err)
 		}
 
+		progress.ClearStaleCount()
+
 		dailyQuestProgress = append(dailyQuestProgress, progress)
 	}
 
 	return dailyQuestProgress, nil
 }
 
+// Will find the user's progress on a daily quest for update.
 func FindDailyQuestProgress(ctx context.Context, tx pgx.Tx, userId uuid.UUID, dailyQuestId int) (DailyQuestProgress, error) {
 	progress := DailyQuestProgress{UserId: userId, DailyQuestId: dailyQuestId}
 
-	query := "SELECT id, count, last_completed_at FROM daily_quest_progress WHERE (user_id = $1 AND daily_quest_id = $2)"
-	err := tx.QueryRow(ctx, query, userId, dailyQuestId).Scan(&progress.Id, &progress.Count, &progress.LastCompletedAt)
+	query := "SELECT id, count, last_completed_at, progressed_at FROM daily_quest_progress WHERE (user_id = $1 AND daily_quest_id = $2) FOR UPDATE"
+	err := tx.QueryRow(ctx, query, userId, dailyQuestId).Scan(&progress.Id, &progress.Count, &progress.LastCompletedAt, &progress.ProgressedAt)
 	if err != nil {
 		return progress, fmt.Errorf("fail to query daily_quest_progress table: %w", err)
 	}
 
+	progress.ClearStaleCount()
+
 	return progress, nil
 }
 
+// Will insert a progress row for every daily quest the user doesn't have one for yet.
+// Called on sign up and sign in so quests added after the user signed up get a row.
 func InsertDailyQuestProgress(ctx context.Context, tx pgx.Tx, dc *DataCache, userId uuid.UUID) error {
 	for _, dailyQuest := range dc.DailyQuests {
 		progress := CreateDailyQuestProgress()
 
-		query := "INSERT INTO daily_quest_progress (user_id, daily_quest_id, last_completed_at) VALUES ($1, $2, $3)"
+		query := `INSERT INTO daily_quest_progress (user_id, daily_quest_id, last_completed_at) VALUES ($1, $2, $3)
+				  ON CONFLICT (user_id, daily_quest_id) DO NOTHING`
 		_, err := tx.Exec(ctx, query, userId, dailyQuest.Id, progress.LastCompletedAt)
 		if err != nil {
 			return fmt.Errorf("fail to insert daily quest progress: %w", err)
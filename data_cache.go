@@ -2,18 +2,95 @@ package main
 
 // Will keep a cache of game data that doesn't get stored in the database.
 type DataCache struct {
-	DailyQuests   []DailyQuest
-	Resources     []Resource
-	UnitTemplates []UnitTemplate
+	Achievements     []Achievement
+	Banners          []Banner
+	Bounties         []BountyTemplate
+	CampaignChapters []CampaignChapterConfig
+	CampaignDrops    []CampaignDropTable
+	DailyQuests      []DailyQuest
+	IdleUpgrades     []IdleUpgrade
+	Items            []ItemTemplate
+	MultiSummon      MultiSummon
+	Resources        []Resource
+	Roster           RosterConfig
+	SummonRates      []SummonRate
+	TypeAdvantage    TypeAdvantage
+	UnitTemplates    []UnitTemplate
+	UnitProgression  UnitProgression
 }
 
 // Initialize the DataCache variables.
 func (dc *DataCache) Load() error {
 	var err error
 
-	dc.DailyQuests = DailyQuests()
 	dc.Resources = Resources()
+
+	dc.Achievements, err = UnMarshalAchievementsJson()
+	if err != nil {
+		return err
+	}
+
 	dc.UnitTemplates, err = UnMarshalUnitTemplatesJson()
+	if err != nil {
+		return err
+	}
+
+	dc.CampaignDrops, err = UnMarshalCampaignDropsJson()
+	if err != nil {
+		return err
+	}
+
+	dc.CampaignChapters, err = UnMarshalCampaignChaptersJson()
+	if err != nil {
+		return err
+	}
+
+	dc.IdleUpgrades, err = UnMarshalIdleUpgradesJson()
+	if err != nil {
+		return err
+	}
+
+	dc.Items, err = UnMarshalItemsJson()
+	if err != nil {
+		return err
+	}
+
+	dc.TypeAdvantage, err = UnMarshalTypeAdvantageJson()
+	if err != nil {
+		return err
+	}
+
+	dc.Roster, err = UnMarshalRosterJson()
+	if err != nil {
+		return err
+	}
+
+	dc.SummonRates, err = UnMarshalSummonRatesJson()
+	if err != nil {
+		return err
+	}
+
+	dc.Banners, err = UnMarshalBannersJson()
+	if err != nil {
+		return err
+	}
+
+	dc.DailyQuests, err = UnMarshalDailyQuestsJson()
+	if err != nil {
+		return err
+	}
+
+	dc.MultiSummon, err = UnMarshalMultiSummonJson()
+	if err != nil {
+		return err
+	}
+
+	dc.Bounties, err = UnMarshalBountiesJson()
+	if err != nil {
+		return err
+	}
+
+	dc.UnitProgression, err = UnMarshalUnitProgressionJson()
 
 	return err
 }
@@ -1,6 +1,19 @@
+DROP TABLE IF EXISTS game_events;
 DROP TABLE IF EXISTS chat_messages;
+DROP TABLE IF EXISTS team_units;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS items;
+DROP TABLE IF EXISTS codex;
+DROP TABLE IF EXISTS summon_history;
+DROP TABLE IF EXISTS banner_pity;
 DROP TABLE IF EXISTS units;
+DROP TABLE IF EXISTS bounties;
+DROP TABLE IF EXISTS achievement_progress;
 DROP TABLE IF EXISTS daily_quest_progress;
+DROP TABLE IF EXISTS idle_upgrades;
+DROP TABLE IF EXISTS prestige;
+DROP TABLE IF EXISTS campaign_chests;
+DROP TABLE IF EXISTS campaign_stages;
 DROP TABLE IF EXISTS campaign;
 DROP TABLE IF EXISTS resources;
 DROP TABLE IF EXISTS users;
@@ -4,6 +4,9 @@ CREATE TABLE IF NOT EXISTS users (
     email varchar(255) NOT NULL,
     pass varchar(255) NOT NULL,
     exp integer NOT NULL DEFAULT 0 CHECK (exp >= 0),
+    unit_expansions integer NOT NULL DEFAULT 0,
+    free_summon_at timestamptz,
+    is_admin boolean NOT NULL DEFAULT false,
     created_at timestamptz NOT NULL,
 
     UNIQUE(name),
@@ -30,17 +33,90 @@ CREATE TABLE IF NOT EXISTS campaign (
     CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS campaign_stages (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    stage integer NOT NULL CHECK (stage >= 1),
+    stars integer NOT NULL CHECK (stars >= 1 AND stars <= 3),
+
+    UNIQUE(user_id, stage),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS campaign_chests (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    chapter integer NOT NULL CHECK (chapter >= 1),
+    chest integer NOT NULL CHECK (chest >= 0),
+    claimed_at timestamptz NOT NULL,
+
+    UNIQUE(user_id, chapter, chest),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS prestige (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    count integer NOT NULL DEFAULT 0 CHECK (count >= 0),
+    lifetime_user_exp bigint NOT NULL DEFAULT 0,
+    lifetime_gold bigint NOT NULL DEFAULT 0,
+    lifetime_exp_stones bigint NOT NULL DEFAULT 0,
+    highest_campaign_level integer NOT NULL DEFAULT 1,
+
+    UNIQUE(user_id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS idle_upgrades (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    upgrade_id integer NOT NULL,
+    level integer NOT NULL DEFAULT 0 CHECK (level >= 0),
+
+    UNIQUE(user_id, upgrade_id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS daily_quest_progress (
     id serial PRIMARY KEY,
     user_id uuid NOT NULL,
     daily_quest_id integer NOT NULL,
     count integer NOT NULL DEFAULT 0,
     last_completed_at timestamptz NOT NULL,
+    progressed_at timestamptz NOT NULL DEFAULT now(),
 
     UNIQUE(user_id, daily_quest_id),
     CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS achievement_progress (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    achievement_id integer NOT NULL,
+    count integer NOT NULL DEFAULT 0,
+    claimed_at timestamptz,
+
+    UNIQUE(user_id, achievement_id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS bounties (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    board_date date NOT NULL,
+    duration integer NOT NULL,
+    unit_type integer NOT NULL,
+    min_stars integer NOT NULL,
+    unit_count integer NOT NULL,
+    reward_type integer NOT NULL,
+    reward_amount integer NOT NULL,
+    reward_item_template integer NOT NULL DEFAULT 0,
+    started_at timestamptz,
+    is_claimed boolean NOT NULL DEFAULT FALSE,
+
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS units (
     id uuid PRIMARY KEY,
     user_id uuid NOT NULL,
@@ -48,10 +124,78 @@ CREATE TABLE IF NOT EXISTS units (
     level integer NOT NULL DEFAULT 1 CHECK (level >= 1),
     stars integer NOT NULL DEFAULT 1 CHECK (stars >= 1 AND stars <= 10),
     is_locked boolean NOT NULL DEFAULT FALSE,
+    bounty_id integer,
+
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_bounty FOREIGN KEY(bounty_id) REFERENCES bounties(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS banner_pity (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    banner_id integer NOT NULL,
+    count integer NOT NULL DEFAULT 0,
+
+    UNIQUE(user_id, banner_id),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS summon_history (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    banner_id integer NOT NULL,
+    template integer NOT NULL,
+    rarity integer NOT NULL,
+    cost_type integer NOT NULL,
+    cost integer NOT NULL,
+    seed bigint NOT NULL,
+    created_at timestamptz NOT NULL,
+
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS codex (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    template integer NOT NULL,
+    highest_stars integer NOT NULL,
+    discovered_at timestamptz NOT NULL,
+
+    UNIQUE(user_id, template),
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS items (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    template integer NOT NULL,
+    unit_id uuid,
+
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_unit FOREIGN KEY(unit_id) REFERENCES units(id) ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS teams (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    name varchar(16) NOT NULL,
+    is_campaign_default boolean NOT NULL DEFAULT FALSE,
 
     CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
+CREATE TABLE IF NOT EXISTS team_units (
+    id serial PRIMARY KEY,
+    team_id integer NOT NULL,
+    unit_id uuid NOT NULL,
+    slot integer NOT NULL CHECK (slot >= 0 AND slot < 5),
+
+    UNIQUE(team_id, slot),
+    UNIQUE(team_id, unit_id),
+    CONSTRAINT fk_team FOREIGN KEY(team_id) REFERENCES teams(id) ON DELETE CASCADE,
+    CONSTRAINT fk_unit FOREIGN KEY(unit_id) REFERENCES units(id) ON DELETE CASCADE
+);
+
 CREATE TABLE IF NOT EXISTS chat_messages (
     id serial PRIMARY KEY,
     user_id uuid NOT NULL,
@@ -61,3 +205,20 @@ CREATE TABLE IF NOT EXISTS chat_messages (
 
     CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
 );
+
+CREATE TABLE IF NOT EXISTS game_events (
+    id serial PRIMARY KEY,
+    user_id uuid NOT NULL,
+    name varchar(32) NOT NULL,
+    data jsonb NOT NULL,
+    created_at timestamptz NOT NULL,
+
+    CONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Columns added after their table was created, existing databases get them here.
+ALTER TABLE daily_quest_progress ADD COLUMN IF NOT EXISTS progressed_at timestamptz NOT NULL DEFAULT now();
+ALTER TABLE users ADD COLUMN IF NOT EXISTS unit_expansions integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS free_summon_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin boolean NOT NULL DEFAULT false;
+ALTER TABLE units ADD COLUMN IF NOT EXISTS bounty_id integer REFERENCES bounties(id) ON DELETE SET NULL;
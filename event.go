@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+)
+
+// A game action performed by a user. Events are published inside the transaction of the action.
+type Event interface {
+	Name() string
+	User() uuid.UUID
+}
+
+// Handles an event inside the transaction of the action, returning an error rolls back the action.
+type EventHandler func(ctx context.Context, tx pgx.Tx, event Event) error
+
+// An in-process event bus. Subscribe every handler before the server starts, the bus is not safe for
+// concurrent subscribes.
+type EventBus struct {
+	handlers    map[reflect.Type][]EventHandler
+	allHandlers []EventHandler
+}
+
+func CreateEventBus() *EventBus {
+	return &EventBus{handlers: make(map[reflect.Type][]EventHandler)}
+}
+
+// Will call the handler for every published event with the same type as the given event.
+func (b *EventBus) Subscribe(event Event, handler EventHandler) {
+	eventType := reflect.TypeOf(event)
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Will call the handler for every published event.
+func (b *EventBus) SubscribeAll(handler EventHandler) {
+	b.allHandlers = append(b.allHandlers, handler)
+}
+
+// Will call the handlers subscribed to the event in the order they subscribed. Publishing stops at the first
+// error, the caller should roll back the transaction.
+func (b *EventBus) Publish(ctx context.Context, tx pgx.Tx, event Event) error {
+	handlers := b.handlers[reflect.TypeOf(event)]
+
+	for _, handler := range append(handlers[:len(handlers):len(handlers)], b.allHandlers...) {
+		if err := handler(ctx, tx, event); err != nil {
+			return fmt.Errorf("fail to handle %v event: %w", event.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// Embedded in every event to identify the user that performed the action.
+type UserEvent struct {
+	UserId uuid.UUID `json:"-"`
+}
+
+func (e UserEvent) User() uuid.UUID {
+	return e.UserId
+}
+
+type SignInEvent struct {
+	UserEvent
+}
+
+func (SignInEvent) Name() string {
+	return "sign_in"
+}
+
+type CampaignCollectEvent struct {
+	UserEvent
+	Transactions []Transaction `json:"transactions"`
+}
+
+func (CampaignCollectEvent) Name() string {
+	return "campaign_collect"
+}
+
+type CampaignBattleEvent struct {
+	UserEvent
+	Stage int  `json:"stage"`
+	Win   bool `json:"win"`
+}
+
+func (CampaignBattleEvent) Name() string {
+	return "campaign_battle"
+}
+
+type ChatSendEvent struct {
+	UserEvent
+	MessageId int `json:"messageId"`
+}
+
+func (ChatSendEvent) Name() string {
+	return "chat_send"
+}
+
+type UnitSummonEvent struct {
+	UserEvent
+	BannerId  int   `json:"bannerId"`
+	Templates []int `json:"templates"`
+}
+
+func (UnitSummonEvent) Name() string {
+	return "unit_summon"
+}
+
+type UnitLevelUpEvent struct {
+	UserEvent
+	UnitId uuid.UUID `json:"unitId"`
+	Levels int       `json:"levels"`
+}
+
+func (UnitLevelUpEvent) Name() string {
+	return "unit_level_up"
+}
@@ -0,0 +1,54 @@
+package main_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/cdrpl/idlemon-server"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestEventBus(t *testing.T) {
+	bus := CreateEventBus()
+	calls := make([]string, 0)
+
+	bus.Subscribe(ChatSendEvent{}, func(ctx context.Context, tx pgx.Tx, event Event) error {
+		calls = append(calls, "chat")
+		return nil
+	})
+
+	bus.SubscribeAll(func(ctx context.Context, tx pgx.Tx, event Event) error {
+		calls = append(calls, "all:"+event.Name())
+		return nil
+	})
+
+	// handlers only receive events of the type they subscribed to
+	if err := bus.Publish(context.Background(), nil, SignInEvent{}); err != nil {
+		t.Fatalf("fail to publish event: %v", err)
+	}
+
+	if err := bus.Publish(context.Background(), nil, ChatSendEvent{}); err != nil {
+		t.Fatalf("fail to publish event: %v", err)
+	}
+
+	expect := []string{"all:sign_in", "chat", "all:chat_send"}
+	if len(calls) != len(expect) {
+		t.Fatalf("expect calls %v, receive: %v", expect, calls)
+	}
+
+	for i := range expect {
+		if calls[i] != expect[i] {
+			t.Fatalf("expect calls %v, receive: %v", expect, calls)
+		}
+	}
+
+	// an error should be returned so the caller rolls back
+	bus.Subscribe(SignInEvent{}, func(ctx context.Context, tx pgx.Tx, event Event) error {
+		return errors.New("handler failed")
+	})
+
+	if err := bus.Publish(context.Background(), nil, SignInEvent{}); err == nil {
+		t.Fatalf("expect publish to return the handler error")
+	}
+}
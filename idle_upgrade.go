@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// An idle upgrade is bought with gold. Every level adds the bonus to the upgrade's campaign modifier.
+// Storage upgrades add hours to the collect cap, rate upgrades add a percent to the resource rate.
+type IdleUpgrade struct {
+	Id         int    `json:"id"`
+	Type       int    `json:"type"`
+	Name       string `json:"name"`
+	MaxLevel   int    `json:"maxLevel"`
+	BaseCost   int    `json:"baseCost"`
+	CostGrowth int    `json:"costGrowth"` // The cost increases by this percent of the base cost every level.
+	Bonus      int    `json:"bonus"`
+}
+
+func UnMarshalIdleUpgradesJson() ([]IdleUpgrade, error) {
+	var data map[string][]IdleUpgrade
+
+	err := json.Unmarshal([]byte(idleUpgradesJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["idleUpgrades"], nil
+}
+
+// Returns the gold cost of buying the next level when the upgrade is at the given level.
+func (u IdleUpgrade) Cost(level int) int {
+	return u.BaseCost * (100 + level*u.CostGrowth) / 100
+}
+
+// Find the idle upgrade with the given ID. The bool will be false if the upgrade doesn't exist.
+func FindIdleUpgrade(dc *DataCache, id int) (IdleUpgrade, bool) {
+	for _, upgrade := range dc.IdleUpgrades {
+		if upgrade.Id == id {
+			return upgrade, true
+		}
+	}
+
+	return IdleUpgrade{}, false
+}
+
+// The level of an idle upgrade owned by a user.
+type IdleUpgradeProgress struct {
+	UpgradeId int `json:"upgradeId"`
+	Level     int `json:"level"`
+}
+
+// Will find the level of every idle upgrade the user has bought.
+func FindIdleUpgrades(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) ([]IdleUpgradeProgress, error) {
+	upgrades := make([]IdleUpgradeProgress, 0)
+
+	query := "SELECT upgrade_id, level FROM idle_upgrades WHERE user_id = $1"
+	rows, err := db.Query(ctx, query, userId)
+	if err != nil {
+		return upgrades, fmt.Errorf("fail to query idle_upgrades table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var upgrade IdleUpgradeProgress
+
+		if err := rows.Scan(&upgrade.UpgradeId, &upgrade.Level); err != nil {
+			return upgrades, fmt.Errorf("fail to scan idle_upgrades row: %w", err)
+		}
+
+		upgrades = append(upgrades, upgrade)
+	}
+
+	return upgrades, rows.Err()
+}
+
+// Will find the level of every idle upgrade the user has bought for update.
+func FindIdleUpgradesLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID) ([]IdleUpgradeProgress, error) {
+	upgrades := make([]IdleUpgradeProgress, 0)
+
+	query := "SELECT upgrade_id, level FROM idle_upgrades WHERE user_id = $1 FOR UPDATE"
+	rows, err := tx.Query(ctx, query, userId)
+	if err != nil {
+		return upgrades, fmt.Errorf("fail to query idle_upgrades table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var upgrade IdleUpgradeProgress
+
+		if err := rows.Scan(&upgrade.UpgradeId, &upgrade.Level); err != nil {
+			return upgrades, fmt.Errorf("fail to scan idle_upgrades row: %w", err)
+		}
+
+		upgrades = append(upgrades, upgrade)
+	}
+
+	return upgrades, rows.Err()
+}
+
+// Will find the level of an idle upgrade for update. Upgrades that were never bought are level 0.
+func FindIdleUpgradeLevelLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, upgradeId int) (int, error) {
+	var level int
+
+	query := "SELECT level FROM idle_upgrades WHERE (user_id = $1 AND upgrade_id = $2) FOR UPDATE"
+	err := tx.QueryRow(ctx, query, userId, upgradeId).Scan(&level)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+
+		return level, fmt.Errorf("fail to query idle_upgrades table: %w", err)
+	}
+
+	return level, nil
+}
+
+// Will increase the level of the idle upgrade by 1.
+func IncIdleUpgradeLevel(ctx context.Context, tx pgx.Tx, userId uuid.UUID, upgradeId int) error {
+	query := `INSERT INTO idle_upgrades (user_id, upgrade_id, level) VALUES ($1, $2, 1)
+			  ON CONFLICT (user_id, upgrade_id) DO UPDATE SET level = idle_upgrades.level + 1`
+
+	_, err := tx.Exec(ctx, query, userId, upgradeId)
+	if err != nil {
+		return fmt.Errorf("fail to upsert idle_upgrades row: %w", err)
+	}
+
+	return nil
+}
+
+// Modifies the amount of resources collected from the campaign. Rates are percentages.
+type CampaignModifiers struct {
+	MaxCollect   time.Duration
+	ExpRate      int
+	GoldRate     int
+	ExpStoneRate int
+}
+
+// Will combine the prestige multiplier and the user's idle upgrades into campaign modifiers.
+func CreateCampaignModifiers(dc *DataCache, prestige Prestige, upgrades []IdleUpgradeProgress) CampaignModifiers {
+	maxCollect := CAMPAIGN_MAX_COLLECT
+	expBonus, goldBonus, expStoneBonus := 0, 0, 0
+
+	for _, progress := range upgrades {
+		upgrade, ok := FindIdleUpgrade(dc, progress.UpgradeId)
+		if !ok {
+			continue
+		}
+
+		bonus := upgrade.Bonus * progress.Level
+
+		switch upgrade.Type {
+		case IDLE_UPGRADE_STORAGE:
+			maxCollect += time.Duration(bonus) * time.Hour
+
+		case IDLE_UPGRADE_EXP_RATE:
+			expBonus += bonus
+
+		case IDLE_UPGRADE_GOLD_RATE:
+			goldBonus += bonus
+
+		case IDLE_UPGRADE_EXP_STONE_RATE:
+			expStoneBonus += bonus
+		}
+	}
+
+	multiplier := prestige.Multiplier()
+
+	return CampaignModifiers{
+		MaxCollect:   maxCollect,
+		ExpRate:      multiplier * (100 + expBonus) / 100,
+		GoldRate:     multiplier * (100 + goldBonus) / 100,
+		ExpStoneRate: multiplier * (100 + expStoneBonus) / 100,
+	}
+}
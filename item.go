@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Equipment that adds flat stat bonuses to the unit wearing it. A unit can wear one item per slot.
+type ItemTemplate struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	Slot int    `json:"slot"`
+	Hp   int    `json:"hp"`
+	Atk  int    `json:"atk"`
+	Def  int    `json:"def"`
+	Spd  int    `json:"spd"`
+}
+
+func UnMarshalItemsJson() ([]ItemTemplate, error) {
+	var data map[string][]ItemTemplate
+
+	err := json.Unmarshal([]byte(itemsJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["items"], nil
+}
+
+// Find the item template with the given ID. The bool will be false if the template doesn't exist.
+func FindItemTemplate(dc *DataCache, id int) (ItemTemplate, bool) {
+	for _, template := range dc.Items {
+		if template.Id == id {
+			return template, true
+		}
+	}
+
+	return ItemTemplate{}, false
+}
+
+// An item in a user's inventory. UnitId is nil if the item is not equipped.
+type Item struct {
+	Id       int        `json:"id"`
+	Template int        `json:"template"`
+	UnitId   *uuid.UUID `json:"unitId"`
+}
+
+// Will insert the given amount of items into the user's inventory.
+func InsertItems(ctx context.Context, tx pgx.Tx, userId uuid.UUID, template int, amount int) error {
+	query := "INSERT INTO items (user_id, template) SELECT $1, $2 FROM generate_series(1, $3)"
+
+	_, err := tx.Exec(ctx, query, userId, template, amount)
+	if err != nil {
+		return fmt.Errorf("fail to insert items rows: %w", err)
+	}
+
+	return nil
+}
+
+// Will equip the item to the unit. Items already worn by the unit in the same slot are unequipped.
+// The unit should be locked for update so that concurrent equips can't fill a slot twice.
+func (i *Item) Equip(ctx context.Context, tx pgx.Tx, dc *DataCache, unitId uuid.UUID) error {
+	template, _ := FindItemTemplate(dc, i.Template)
+
+	sameSlot := make([]int, 0)
+	for _, other := range dc.Items {
+		if other.Slot == template.Slot {
+			sameSlot = append(sameSlot, other.Id)
+		}
+	}
+
+	query := "UPDATE items SET unit_id = NULL WHERE (unit_id = $1 AND template = ANY($2) AND id != $3)"
+	if _, err := tx.Exec(ctx, query, unitId, sameSlot, i.Id); err != nil {
+		return fmt.Errorf("fail to unequip items: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE items SET unit_id = $1 WHERE id = $2", unitId, i.Id); err != nil {
+		return fmt.Errorf("fail to update items row: %w", err)
+	}
+
+	i.UnitId = &unitId
+
+	return nil
+}
+
+// Will remove the item from the unit wearing it.
+func (i *Item) Unequip(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, "UPDATE items SET unit_id = NULL WHERE id = $1", i.Id); err != nil {
+		return fmt.Errorf("fail to update items row: %w", err)
+	}
+
+	i.UnitId = nil
+
+	return nil
+}
+
+// Will find every item in the user's inventory.
+func FindItems(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) ([]Item, error) {
+	items := make([]Item, 0)
+
+	query := "SELECT id, template, unit_id FROM items WHERE user_id = $1 ORDER BY id"
+	rows, err := db.Query(ctx, query, userId)
+	if err != nil {
+		return items, fmt.Errorf("fail to query items table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item Item
+
+		if err := rows.Scan(&item.Id, &item.Template, &item.UnitId); err != nil {
+			return items, fmt.Errorf("fail to scan items row: %w", err)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// Will find an item owned by the user for update.
+func FindItemLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, itemId int) (Item, error) {
+	item := Item{Id: itemId}
+
+	query := "SELECT template, unit_id FROM items WHERE (id = $1 AND user_id = $2) FOR UPDATE"
+	err := tx.QueryRow(ctx, query, itemId, userId).Scan(&item.Template, &item.UnitId)
+	if err != nil {
+		return item, fmt.Errorf("fail to query items row: %w", err)
+	}
+
+	return item, nil
+}
@@ -27,9 +27,48 @@ var upSql string
 //go:embed database_down.sql
 var downSql string
 
+//go:embed achievements.json
+var achievementsJson string
+
 //go:embed unit_templates.json
 var unitTemplatesJson string
 
+//go:embed bounties.json
+var bountiesJson string
+
+//go:embed daily_quests.json
+var dailyQuestsJson string
+
+//go:embed unit_progression.json
+var unitProgressionJson string
+
+//go:embed campaign_drops.json
+var campaignDropsJson string
+
+//go:embed campaign_chapters.json
+var campaignChaptersJson string
+
+//go:embed idle_upgrades.json
+var idleUpgradesJson string
+
+//go:embed items.json
+var itemsJson string
+
+//go:embed type_advantages.json
+var typeAdvantagesJson string
+
+//go:embed roster.json
+var rosterJson string
+
+//go:embed summon_rates.json
+var summonRatesJson string
+
+//go:embed banners.json
+var bannersJson string
+
+//go:embed multi_summon.json
+var multiSummonJson string
+
 func main() {
 	CreateIdlemonServer().Run()
 }
@@ -40,6 +79,7 @@ type IdlemonServer struct {
 	Rdb        *redis.Client
 	WsHub      *WsHub
 	DataCache  *DataCache
+	Rng        Rng
 }
 
 func CreateIdlemonServer() *IdlemonServer {
@@ -91,8 +131,6 @@ func CreateIdlemonServer() *IdlemonServer {
 	log.Println("connecting to redis")
 	rdb := CreateRedisClient(ctx)
 
-	SeedRand()
-
 	// setup WebSocket
 	upgrader := websocket.Upgrader{
 		ReadBufferSize:  WS_READ_BUFFER_SIZE,
@@ -100,7 +138,15 @@ func CreateIdlemonServer() *IdlemonServer {
 	}
 	wsHub := CreateWsHub(upgrader)
 
-	controller := CreateController(db, rdb, wsHub, dataCache)
+	rng := CreateCryptoRng()
+
+	// subscribers react to game actions inside the action's transaction
+	bus := CreateEventBus()
+	SubscribeDailyQuests(bus, dataCache)
+	SubscribeAchievements(bus, dataCache)
+	SubscribeAnalytics(bus)
+
+	controller := CreateController(db, rdb, wsHub, dataCache, rng, bus)
 
 	port := fmt.Sprintf(":%v", os.Getenv("PORT"))
 	httpServer := &http.Server{
@@ -114,6 +160,7 @@ func CreateIdlemonServer() *IdlemonServer {
 		Rdb:        rdb,
 		WsHub:      wsHub,
 		DataCache:  dataCache,
+		Rng:        rng,
 	}
 }
 
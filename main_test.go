@@ -99,8 +99,13 @@ func AuthenticatedUser(t *testing.T, db *pgxpool.Pool, rdb *redis.Client, dataCa
 
 // Create a random unit and insert it into the table.
 func InsertRandUnit(t *testing.T, db *pgxpool.Pool, dataCache *DataCache, userId uuid.UUID) Unit {
-	template := RandUnitTemplateID(dataCache)
+	template := dataCache.UnitTemplates[idlemonServer.Rng.Int(0, len(dataCache.UnitTemplates)-1)].ID
 
+	return InsertTemplateUnit(t, db, userId, template)
+}
+
+// Create a unit with the given template and insert it into the table.
+func InsertTemplateUnit(t *testing.T, db *pgxpool.Pool, userId uuid.UUID, template int) Unit {
 	tx, err := db.Begin(context.Background())
 	if err != nil {
 		t.Fatalf("fail to begin transaction: %v", err)
@@ -12,6 +12,7 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -98,6 +99,33 @@ func (rt RequireTokenMiddleware) Middleware(next httprouter.Handle) httprouter.H
 	}
 }
 
+func CreateRequireAdminMiddleware(db *pgxpool.Pool) RequireAdminMiddleware {
+	return RequireAdminMiddleware{db: db}
+}
+
+// This middleware will reject requests from users other than the admin user.
+// It must run after the require token middleware.
+type RequireAdminMiddleware struct {
+	db *pgxpool.Pool
+}
+
+// If the user is not the admin user, a forbidden error response will be written.
+func (ra RequireAdminMiddleware) Middleware(next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		isAdmin, err := IsAdminUser(r.Context(), ra.db, GetUserId(r))
+		if err != nil {
+			ErrResSanitize(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if isAdmin {
+			next(w, r, p)
+		} else {
+			ErrRes(w, http.StatusForbidden)
+		}
+	}
+}
+
 // Extract the id and token split by a colon.
 func ParseAuthHeader(authorization string) (id string, token string) {
 	index := strings.Index(authorization, ":")
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Tracks the number of times a user has prestiged and their lifetime stats.
+type Prestige struct {
+	Id                   int       `json:"id"`
+	UserId               uuid.UUID `json:"-"`
+	Count                int       `json:"count"`
+	LifetimeUserExp      int       `json:"lifetimeUserExp"`
+	LifetimeGold         int       `json:"lifetimeGold"`
+	LifetimeExpStones    int       `json:"lifetimeExpStones"`
+	HighestCampaignLevel int       `json:"highestCampaignLevel"`
+}
+
+// Returns the resources that are reset to 0 when prestiging.
+func PrestigeResetResources() []int {
+	return []int{RESOURCE_GOLD, RESOURCE_EXP_STONE}
+}
+
+// Returns the campaign collect rate multiplier as a percentage.
+func (p *Prestige) Multiplier() int {
+	return 100 + p.Count*PRESTIGE_MULTIPLIER
+}
+
+// Will reset the campaign and prestige resources then increase the prestige count.
+// Claimed chapter chests are kept so their rewards cannot be claimed again after prestiging.
+// The campaign, prestige, and resource rows should already be locked by the transaction.
+func (p *Prestige) Reset(ctx context.Context, tx pgx.Tx, campaign *Campaign) error {
+	campaign.Level = 1
+	campaign.LastCollectedAt = time.Now()
+
+	query := "UPDATE campaign SET level = $1, last_collected_at = $2 WHERE id = $3"
+	_, err := tx.Exec(ctx, query, campaign.Level, campaign.LastCollectedAt, campaign.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update campaign row: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM campaign_stages WHERE user_id = $1", p.UserId); err != nil {
+		return fmt.Errorf("fail to delete campaign_stages rows: %w", err)
+	}
+
+	for _, resourceType := range PrestigeResetResources() {
+		query := "UPDATE resources SET amount = 0 WHERE (user_id = $1 AND type = $2)"
+
+		if _, err := tx.Exec(ctx, query, p.UserId, resourceType); err != nil {
+			return fmt.Errorf("fail to reset resource of type %v: %w", resourceType, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE prestige SET count = count + 1 WHERE id = $1", p.Id); err != nil {
+		return fmt.Errorf("fail to update prestige row: %w", err)
+	}
+
+	p.Count++
+
+	return nil
+}
+
+// Will add the collected campaign rewards to the lifetime stats.
+func IncPrestigeLifetimeStats(ctx context.Context, tx pgx.Tx, userId uuid.UUID, exp int, gold int, expStones int) error {
+	query := `UPDATE prestige SET lifetime_user_exp = lifetime_user_exp + $1, lifetime_gold = lifetime_gold + $2,
+			  lifetime_exp_stones = lifetime_exp_stones + $3 WHERE user_id = $4`
+
+	_, err := tx.Exec(ctx, query, exp, gold, expStones, userId)
+	if err != nil {
+		return fmt.Errorf("fail to update prestige row: %w", err)
+	}
+
+	return nil
+}
+
+// Will update the highest campaign level if the given level is higher.
+func UpdateHighestCampaignLevel(ctx context.Context, tx pgx.Tx, userId uuid.UUID, level int) error {
+	query := "UPDATE prestige SET highest_campaign_level = GREATEST(highest_campaign_level, $1) WHERE user_id = $2"
+
+	_, err := tx.Exec(ctx, query, level, userId)
+	if err != nil {
+		return fmt.Errorf("fail to update prestige row: %w", err)
+	}
+
+	return nil
+}
+
+// Will find the user's prestige. Users without a prestige row have not prestiged yet.
+func FindPrestige(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) (Prestige, error) {
+	prestige := Prestige{UserId: userId, HighestCampaignLevel: 1}
+
+	query := "SELECT id, count, lifetime_user_exp, lifetime_gold, lifetime_exp_stones, highest_campaign_level FROM prestige WHERE user_id = $1"
+	err := db.QueryRow(ctx, query, userId).Scan(&prestige.Id, &prestige.Count, &prestige.LifetimeUserExp, &prestige.LifetimeGold, &prestige.LifetimeExpStones, &prestige.HighestCampaignLevel)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return prestige, fmt.Errorf("fail to query prestige row: %w", err)
+	}
+
+	return prestige, nil
+}
+
+// Will find the user's prestige row for update. The row is inserted first for users who signed up before prestige existed.
+func FindPrestigeLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID) (Prestige, error) {
+	prestige := Prestige{UserId: userId}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO prestige (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING", userId); err != nil {
+		return prestige, fmt.Errorf("fail to insert prestige row: %w", err)
+	}
+
+	query := "SELECT id, count, lifetime_user_exp, lifetime_gold, lifetime_exp_stones, highest_campaign_level FROM prestige WHERE user_id = $1 FOR UPDATE"
+	err := tx.QueryRow(ctx, query, userId).Scan(&prestige.Id, &prestige.Count, &prestige.LifetimeUserExp, &prestige.LifetimeGold, &prestige.LifetimeExpStones, &prestige.HighestCampaignLevel)
+	if err != nil {
+		return prestige, fmt.Errorf("fail to query prestige row: %w", err)
+	}
+
+	return prestige, nil
+}
+
+func InsertPrestige(ctx context.Context, tx pgx.Tx, userId uuid.UUID) error {
+	query := "INSERT INTO prestige (user_id) VALUES ($1)"
+
+	_, err := tx.Exec(ctx, query, userId)
+	if err != nil {
+		return fmt.Errorf("fail to insert prestige row: %w", err)
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 	"math/big"
 	mathRand "math/rand"
+	"sync"
 )
 
 // Generate a random string.
@@ -26,16 +27,101 @@ func GenerateToken(length int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// Seed math rand using crypto rand. Will exit app if error is encountered.
-func SeedRand() {
-	n, err := rand.Int(rand.Reader, big.NewInt(math.MaxInt64))
+// A source of random numbers for game logic. Rolls that need to be audited or replayed should use a generator
+// created by Fork and record its seed, CreateSeededRng with the same seed will repeat the rolls.
+type Rng interface {
+	Int(min int, max int) int   // Returns a random integer between min and max inclusive.
+	Chance(percent int) bool    // Returns true with the given percent chance.
+	Weighted(weights []int) int // Returns an index of the weights, the chance of each index is proportional to its weight.
+	Fork() (Rng, int64)         // Returns a new seeded generator and its seed.
+}
+
+// Will pick an index of the weights slice. The chance of each index being picked is proportional to its weight.
+func pickWeighted(rng Rng, weights []int) int {
+	total := 0
+	for _, weight := range weights {
+		total += weight
+	}
+
+	if total <= 0 {
+		return 0
+	}
+
+	roll := rng.Int(0, total-1)
+
+	for i, weight := range weights {
+		if roll < weight {
+			return i
+		}
+
+		roll -= weight
+	}
+
+	return len(weights) - 1
+}
+
+// The production generator, every roll reads from crypto rand. Safe for concurrent use.
+type CryptoRng struct{}
+
+func CreateCryptoRng() CryptoRng {
+	return CryptoRng{}
+}
+
+// Will exit app if crypto rand fails.
+func (CryptoRng) Int(min int, max int) int {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max-min+1)))
 	if err != nil {
-		log.Fatalln("could not seed rand:", err)
+		log.Fatalln("could not read crypto rand:", err)
 	}
 
-	mathRand.Seed(n.Int64())
+	return min + int(n.Int64())
+}
+
+func (r CryptoRng) Chance(percent int) bool {
+	return r.Int(0, 99) < percent
 }
 
-func RandInt(min int, max int) int {
-	return mathRand.Int()%max + 1
+func (r CryptoRng) Weighted(weights []int) int {
+	return pickWeighted(r, weights)
+}
+
+// The seed of the new generator is read from crypto rand.
+func (r CryptoRng) Fork() (Rng, int64) {
+	seed := int64(r.Int(0, math.MaxInt64-1))
+
+	return CreateSeededRng(seed), seed
+}
+
+// A deterministic generator, the same seed always produces the same rolls. Safe for concurrent use.
+type SeededRng struct {
+	mu   sync.Mutex
+	rand *mathRand.Rand
+}
+
+func CreateSeededRng(seed int64) *SeededRng {
+	return &SeededRng{rand: mathRand.New(mathRand.NewSource(seed))}
+}
+
+func (r *SeededRng) Int(min int, max int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return min + r.rand.Intn(max-min+1)
+}
+
+func (r *SeededRng) Chance(percent int) bool {
+	return r.Int(0, 99) < percent
+}
+
+func (r *SeededRng) Weighted(weights []int) int {
+	return pickWeighted(r, weights)
+}
+
+// The seed of the new generator is drawn from this generator so forks are deterministic too.
+func (r *SeededRng) Fork() (Rng, int64) {
+	r.mu.Lock()
+	seed := r.rand.Int63()
+	r.mu.Unlock()
+
+	return CreateSeededRng(seed), seed
 }
@@ -0,0 +1,51 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/cdrpl/idlemon-server"
+)
+
+func TestSeededRng(t *testing.T) {
+	a := CreateSeededRng(42)
+	b := CreateSeededRng(42)
+
+	// the same seed should produce the same rolls
+	for i := 0; i < 100; i++ {
+		if x, y := a.Int(1, 1000), b.Int(1, 1000); x != y {
+			t.Fatalf("expect rolls to match, receive: %v and %v", x, y)
+		}
+	}
+
+	// a fork should be repeated by a generator with the fork's seed
+	fork, seed := a.Fork()
+	replay := CreateSeededRng(seed)
+
+	for i := 0; i < 100; i++ {
+		if x, y := fork.Weighted([]int{1, 2, 3}), replay.Weighted([]int{1, 2, 3}); x != y {
+			t.Fatalf("expect rolls to match, receive: %v and %v", x, y)
+		}
+	}
+}
+
+func TestRngInt(t *testing.T) {
+	for _, rng := range []Rng{CreateCryptoRng(), CreateSeededRng(1)} {
+		for i := 0; i < 1000; i++ {
+			if n := rng.Int(3, 5); n < 3 || n > 5 {
+				t.Fatalf("expect roll between 3 and 5 inclusive, receive: %v", n)
+			}
+		}
+
+		if rng.Chance(0) {
+			t.Errorf("expect 0 percent chance to be false")
+		}
+
+		if !rng.Chance(100) {
+			t.Errorf("expect 100 percent chance to be true")
+		}
+
+		if i := rng.Weighted([]int{0, 5, 0}); i != 1 {
+			t.Errorf("expect index 1 to be picked, receive: %v", i)
+		}
+	}
+}
@@ -5,12 +5,40 @@ import (
 	"fmt"
 	"net/mail"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 type RequestDTO interface {
 	Validate() error
 }
 
+type CampaignBattleReq struct {
+	Stage   int         `json:"stage"`
+	UnitIds []uuid.UUID `json:"unitIds"`
+}
+
+// The default campaign team is used if no unit IDs are given.
+func (r *CampaignBattleReq) Validate() error {
+	if r.Stage < 1 {
+		return errors.New("stage must be greater than 0")
+	}
+
+	if len(r.UnitIds) == 0 {
+		return nil
+	}
+
+	return ValidateUnitIds(r.UnitIds, BATTLE_MAX_TEAM_SIZE)
+}
+
+type BountyDispatchReq struct {
+	UnitIds []uuid.UUID `json:"unitIds"`
+}
+
+func (r *BountyDispatchReq) Validate() error {
+	return ValidateUnitIds(r.UnitIds, BOUNTY_MAX_UNITS)
+}
+
 type ChatMessageSendReq struct {
 	Message string `json:"message"`
 }
@@ -31,6 +59,18 @@ func (r *ChatMessageSendReq) Validate() error {
 	return nil
 }
 
+type ItemEquipReq struct {
+	UnitId uuid.UUID `json:"unitId"`
+}
+
+func (r *ItemEquipReq) Validate() error {
+	if r.UnitId == uuid.Nil {
+		return errors.New("unit ID is required")
+	}
+
+	return nil
+}
+
 type SignUpReq struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
@@ -78,6 +118,82 @@ func (r *SignInReq) Validate() error {
 	return nil
 }
 
+type TeamReq struct {
+	Name  string     `json:"name"`
+	Units []TeamSlot `json:"units"`
+}
+
+func (r *TeamReq) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+
+	if len(r.Name) < TEAM_NAME_MIN {
+		return fmt.Errorf("team name must have at least %v characters", TEAM_NAME_MIN)
+	}
+
+	if len(r.Name) > TEAM_NAME_MAX {
+		return fmt.Errorf("team name cannot have more than %v characters", TEAM_NAME_MAX)
+	}
+
+	slots := make(map[int]bool)
+	unitIds := make([]uuid.UUID, len(r.Units))
+
+	for i, unit := range r.Units {
+		if unit.Slot < 0 || unit.Slot >= BATTLE_MAX_TEAM_SIZE {
+			return fmt.Errorf("slot must be between 0 and %v", BATTLE_MAX_TEAM_SIZE-1)
+		}
+
+		if slots[unit.Slot] {
+			return errors.New("units cannot share a slot")
+		}
+
+		slots[unit.Slot] = true
+		unitIds[i] = unit.UnitId
+	}
+
+	return ValidateUnitIds(unitIds, BATTLE_MAX_TEAM_SIZE)
+}
+
+type UnitBulkLockReq struct {
+	UnitIds []uuid.UUID `json:"unitIds"`
+	Locked  bool        `json:"locked"`
+}
+
+func (r *UnitBulkLockReq) Validate() error {
+	return ValidateUnitIds(r.UnitIds, UNIT_MAX_BULK)
+}
+
+type UnitEvolveReq struct {
+	FodderIds []uuid.UUID `json:"fodderIds"`
+}
+
+func (r *UnitEvolveReq) Validate() error {
+	return ValidateUnitIds(r.FodderIds, UNIT_MAX_FODDER)
+}
+
+type UnitLevelUpReq struct {
+	Levels int `json:"levels"`
+}
+
+func (r *UnitLevelUpReq) Validate() error {
+	if r.Levels < 1 {
+		return errors.New("levels must be greater than 0")
+	}
+
+	if r.Levels > UNIT_MAX_LEVEL_UPS {
+		return fmt.Errorf("levels cannot be greater than %v", UNIT_MAX_LEVEL_UPS)
+	}
+
+	return nil
+}
+
+type UnitRetireReq struct {
+	UnitIds []uuid.UUID `json:"unitIds"`
+}
+
+func (r *UnitRetireReq) Validate() error {
+	return ValidateUnitIds(r.UnitIds, UNIT_MAX_BULK)
+}
+
 type UserRenameReq struct {
 	Name string `json:"name"`
 }
@@ -127,3 +243,26 @@ func ValidateUserPass(pass string) error {
 
 	return nil
 }
+
+// Validate a list of unit IDs. There must be at least 1 unit and no duplicates.
+func ValidateUnitIds(unitIds []uuid.UUID, max int) error {
+	if len(unitIds) < 1 {
+		return errors.New("unitIds must have at least 1 unit")
+	}
+
+	if len(unitIds) > max {
+		return fmt.Errorf("unitIds cannot have more than %v units", max)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+
+	for _, id := range unitIds {
+		if seen[id] {
+			return errors.New("unitIds cannot have duplicate units")
+		}
+
+		seen[id] = true
+	}
+
+	return nil
+}
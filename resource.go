@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -18,12 +19,13 @@ type Resource struct {
 
 // Return a slice of all resources in the game.
 func Resources() []Resource {
-	resources := make([]Resource, 4)
+	resources := make([]Resource, 5)
 
 	resources[RESOURCE_GOLD] = Resource{Type: RESOURCE_GOLD}
 	resources[RESOURCE_GEMS] = Resource{Type: RESOURCE_GEMS}
 	resources[RESOURCE_EXP_STONE] = Resource{Type: RESOURCE_EXP_STONE}
 	resources[RESOURCE_EVO_STONE] = Resource{Type: RESOURCE_EVO_STONE}
+	resources[RESOURCE_SUMMON_TICKET] = Resource{Type: RESOURCE_SUMMON_TICKET}
 
 	return resources
 }
@@ -52,13 +54,14 @@ func FindResources(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) ([]R
 	return resources, nil
 }
 
-// Will find a resource for update.
+// Will find a resource for update. Resources added after the user signed up have no row until they are
+// first increased, their amount will be 0.
 func FindResourceLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, resourceType int) (Resource, error) {
 	resource := Resource{Type: resourceType}
 
 	query := "SELECT id, amount FROM resources WHERE (user_id = $1 AND type = $2) FOR UPDATE"
 	err := tx.QueryRow(ctx, query, userId, resourceType).Scan(&resource.Id, &resource.Amount)
-	if err != nil {
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return resource, fmt.Errorf("fail to query resources table: %w", err)
 	}
 
@@ -80,9 +83,11 @@ func InsertResources(ctx context.Context, tx pgx.Tx, dc *DataCache, userId uuid.
 }
 
 // Will increase a resource row in the database by the specific amount.
+// The row is inserted if the resource was added after the user signed up.
 func IncResource(ctx context.Context, tx pgx.Tx, userId uuid.UUID, resourceType int, amount int) error {
-	query := "UPDATE resources SET amount = amount + $1 WHERE (user_id = $2 AND type = $3)"
-	_, err := tx.Exec(ctx, query, amount, userId, resourceType)
+	query := `INSERT INTO resources (user_id, type, amount) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id, type) DO UPDATE SET amount = resources.amount + $3`
+	_, err := tx.Exec(ctx, query, userId, resourceType, amount)
 
 	return err
 }
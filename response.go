@@ -9,6 +9,7 @@ import (
 
 type ErrorResponse struct {
 	Message string `json:"message"`
+	Code    int    `json:"code,omitempty"` // Lets the client handle specific errors, see the error code constants.
 }
 
 // Will write a successful json response. The data must be a valid target for JSON encoding.
@@ -33,6 +34,12 @@ func ErrResCustom(w http.ResponseWriter, code int, msg string) {
 	WriteJsonRes(w, code, e)
 }
 
+// Writes an error response using a custom message and an error code.
+func ErrResCode(w http.ResponseWriter, code int, errCode int, msg string) {
+	e := ErrorResponse{Message: msg, Code: errCode}
+	WriteJsonRes(w, code, e)
+}
+
 // Will write an error response with a custom message.
 // If the ENV env var is set to production the message will be replaced with a standard one based on the HTTP code.
 func ErrResSanitize(w http.ResponseWriter, code int, msg string) {
@@ -7,27 +7,167 @@ type ChatMessageGetRes struct {
 }
 
 type SignInRes struct {
-	Token              string               `json:"token"`
-	User               User                 `json:"user"`
-	Campaign           Campaign             `json:"campaign"`
-	DailyQuestProgress []DailyQuestProgress `json:"dailyQuestProgress"`
-	Resources          []Resource           `json:"resources"`
-	Units              []Unit               `json:"units"`
-	UnitTemplates      []UnitTemplate       `json:"unitTemplates"`
+	Token               string                `json:"token"`
+	User                User                  `json:"user"`
+	AchievementProgress []AchievementProgress `json:"achievementProgress"`
+	Campaign            Campaign              `json:"campaign"`
+	CampaignChapters    []CampaignChapter     `json:"campaignChapters"`
+	DailyQuestProgress  []DailyQuestProgress  `json:"dailyQuestProgress"`
+	IdleUpgrades        []IdleUpgradeProgress `json:"idleUpgrades"`
+	Items               []Item                `json:"items"`
+	Prestige            Prestige              `json:"prestige"`
+	Resources           []Resource            `json:"resources"`
+	Roster              Roster                `json:"roster"`
+	Units               []Unit                `json:"units"`
+	UnitTemplates       []UnitTemplate        `json:"unitTemplates"`
+	AchievementData     []Achievement         `json:"achievementData"`
+	DailyQuestData      []DailyQuest          `json:"dailyQuestData"`
+	IdleUpgradeData     []IdleUpgrade         `json:"idleUpgradeData"`
+	ItemData            []ItemTemplate        `json:"itemData"`
+	TypeAdvantageData   TypeAdvantage         `json:"typeAdvantageData"`
+	NextFreeSummonAt    time.Time             `json:"nextFreeSummonAt"`
 }
 
 type CampaignCollectRes struct {
-	Transactions    [3]Transaction `json:"transactions"`
-	LastCollectedAt time.Time      `json:"lastCollectedAt"`
+	Transactions    []Transaction `json:"transactions"`
+	LastCollectedAt time.Time     `json:"lastCollectedAt"`
+}
+
+type AchievementListRes struct {
+	Progress []AchievementProgress `json:"progress"`
+}
+
+type AchievementClaimRes struct {
+	Rewards []Transaction `json:"rewards"`
+}
+
+type BountyBoardRes struct {
+	Bounties []Bounty `json:"bounties"`
+}
+
+type BountyBoardRefreshRes struct {
+	Bounties    []Bounty    `json:"bounties"`
+	Transaction Transaction `json:"transaction"`
+}
+
+type BountyDispatchRes struct {
+	Bounty Bounty `json:"bounty"`
+}
+
+type BountyClaimRes struct {
+	Transaction Transaction `json:"transaction"`
+}
+
+type CampaignBattleRes struct {
+	Result   BattleResult `json:"result"`
+	Stars    int          `json:"stars"`
+	Campaign Campaign     `json:"campaign"`
+}
+
+type CampaignChestClaimRes struct {
+	Transaction Transaction `json:"transaction"`
 }
 
 type DailyQuestCompleteRes struct {
-	Status      int         `json:"status"`
-	Message     string      `json:"message"`
+	Status  int           `json:"status"`
+	Message string        `json:"message"`
+	Rewards []Transaction `json:"rewards"`
+}
+
+type IdleUpgradeBuyRes struct {
+	Upgrade     IdleUpgradeProgress `json:"upgrade"`
+	Transaction Transaction         `json:"transaction"`
+}
+
+type PrestigeRes struct {
+	Prestige Prestige `json:"prestige"`
+	Campaign Campaign `json:"campaign"`
+}
+
+type RosterExpandRes struct {
+	Roster      Roster      `json:"roster"`
 	Transaction Transaction `json:"transaction"`
 }
 
+type SummonBannersRes struct {
+	Banners []Banner     `json:"banners"`
+	Pities  []BannerPity `json:"pities"`
+}
+
+type SummonHistoryRes struct {
+	Records    []SummonRecord `json:"records"`
+	NextCursor string         `json:"nextCursor"` // Empty if there are no more records.
+}
+
+type SummonMultiRes struct {
+	Units       []Unit        `json:"units"`
+	Transaction Transaction   `json:"transaction"`
+	Rewards     []Transaction `json:"rewards"` // Codex discovery rewards.
+	Pity        BannerPity    `json:"pity"`
+}
+
+type SummonRatesRes struct {
+	Rates []RarityRate `json:"rates"`
+}
+
+type SummonReportRes struct {
+	Total int                `json:"total"`
+	Rates []SummonRateReport `json:"rates"`
+}
+
 type SummonUnitRes struct {
+	Unit             Unit          `json:"unit"`
+	Transaction      Transaction   `json:"transaction"`
+	Rewards          []Transaction `json:"rewards"` // Codex discovery rewards.
+	Pity             BannerPity    `json:"pity"`
+	NextFreeSummonAt time.Time     `json:"nextFreeSummonAt"`
+}
+
+type CodexRes struct {
+	Codex []CodexEntry `json:"codex"`
+}
+
+type ItemsRes struct {
+	Items []Item `json:"items"`
+}
+
+type ItemEquipRes struct {
+	Item Item `json:"item"`
+	Unit Unit `json:"unit"`
+}
+
+type TeamRes struct {
+	Team Team `json:"team"`
+}
+
+type TeamsRes struct {
+	Teams []Team `json:"teams"`
+}
+
+type UnitsRes struct {
+	Units      []Unit `json:"units"`
+	NextCursor string `json:"nextCursor"` // Empty if there are no more units.
+}
+
+type UnitRes struct {
+	Unit Unit `json:"unit"`
+}
+
+type UnitEvolveRes struct {
 	Unit        Unit        `json:"unit"`
 	Transaction Transaction `json:"transaction"`
 }
+
+type UnitLevelUpRes struct {
+	Unit         Unit          `json:"unit"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+type UnitBulkLockRes struct {
+	Results []UnitResult `json:"results"`
+}
+
+type UnitRetireRes struct {
+	Transactions []Transaction `json:"transactions"`
+	Results      []UnitResult  `json:"results"`
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Game data describing how many units a user can own. Every expansion adds the expansion size to the capacity.
+type RosterConfig struct {
+	DefaultCapacity int `json:"defaultCapacity"`
+	ExpansionSize   int `json:"expansionSize"`
+	ExpansionCost   int `json:"expansionCost"` // Gem cost of a single expansion.
+	MaxExpansions   int `json:"maxExpansions"`
+}
+
+func UnMarshalRosterJson() (RosterConfig, error) {
+	var data map[string]RosterConfig
+
+	err := json.Unmarshal([]byte(rosterJson), &data)
+	if err != nil {
+		return RosterConfig{}, err
+	}
+
+	return data["roster"], nil
+}
+
+// The number of units a user owns and how many they can own.
+type Roster struct {
+	Expansions int `json:"expansions"`
+	Capacity   int `json:"capacity"`
+	Count      int `json:"count"`
+}
+
+func CreateRoster(dc *DataCache, expansions int, count int) Roster {
+	return Roster{
+		Expansions: expansions,
+		Capacity:   dc.Roster.DefaultCapacity + expansions*dc.Roster.ExpansionSize,
+		Count:      count,
+	}
+}
+
+// Returns true if the given number of units can be added to the roster.
+func (r Roster) HasSpace(units int) bool {
+	return r.Count+units <= r.Capacity
+}
+
+// Will add an expansion to the user's roster.
+func (r *Roster) Expand(ctx context.Context, tx pgx.Tx, dc *DataCache, userId uuid.UUID) error {
+	_, err := tx.Exec(ctx, "UPDATE users SET unit_expansions = unit_expansions + 1 WHERE id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("fail to update users row: %w", err)
+	}
+
+	*r = CreateRoster(dc, r.Expansions+1, r.Count)
+
+	return nil
+}
+
+// Will find the user's roster.
+func FindRoster(ctx context.Context, db *pgxpool.Pool, dc *DataCache, userId uuid.UUID) (Roster, error) {
+	var expansions, count int
+
+	query := "SELECT unit_expansions, (SELECT COUNT(*) FROM units WHERE user_id = $1) FROM users WHERE id = $1"
+	err := db.QueryRow(ctx, query, userId).Scan(&expansions, &count)
+	if err != nil {
+		return Roster{}, fmt.Errorf("fail to query users row: %w", err)
+	}
+
+	return CreateRoster(dc, expansions, count), nil
+}
+
+// Will find the user's roster and lock the user row. Any route granting units should call this first
+// so that concurrent grants can't go over the capacity.
+func FindRosterLock(ctx context.Context, tx pgx.Tx, dc *DataCache, userId uuid.UUID) (Roster, error) {
+	var expansions, count int
+
+	query := "SELECT unit_expansions FROM users WHERE id = $1 FOR UPDATE"
+	if err := tx.QueryRow(ctx, query, userId).Scan(&expansions); err != nil {
+		return Roster{}, fmt.Errorf("fail to query users row: %w", err)
+	}
+
+	query = "SELECT COUNT(*) FROM units WHERE user_id = $1"
+	if err := tx.QueryRow(ctx, query, userId).Scan(&count); err != nil {
+		return Roster{}, fmt.Errorf("fail to count units: %w", err)
+	}
+
+	return CreateRoster(dc, expansions, count), nil
+}
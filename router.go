@@ -12,6 +12,7 @@ func CreateRouter(controller *Controller) *httprouter.Router {
 
 	// middleware
 	auth := CreateRequireTokenMiddleware(controller.rdb).Middleware
+	admin := CreateRequireAdminMiddleware(controller.db).Middleware
 	body := BodyParserMiddleware
 
 	// shorthand reflect TypeOf
@@ -22,21 +23,68 @@ func CreateRouter(controller *Controller) *httprouter.Router {
 	router.GET("/version", controller.Version)
 	router.GET("/robots.txt", controller.Robots)
 
+	// achievement routes
+	router.GET("/achievement", auth(controller.AchievementList))
+	router.PUT("/achievement/:id/claim", auth(controller.AchievementClaim))
+
+	// bounty routes
+	router.GET("/bounty-board", auth(controller.BountyBoard))
+	router.PUT("/bounty-board/refresh", auth(controller.BountyBoardRefresh))
+	router.PUT("/bounty/:id/dispatch", auth(body(typeOf(BountyDispatchReq{}), controller.BountyDispatch)))
+	router.PUT("/bounty/:id/claim", auth(controller.BountyClaim))
+
 	// campaign routes
 	router.PUT("/campaign/collect", auth(controller.CampaignCollect))
+	router.PUT("/campaign/battle", auth(body(typeOf(CampaignBattleReq{}), controller.CampaignBattle)))
+	router.PUT("/campaign/chapter/:chapter/chest/:chest", auth(controller.CampaignChestClaim))
 
 	// chat routes
 	router.GET("/chat/message/history", auth(controller.ChatMessageHistory))
 	router.POST("/chat/message/send", auth(body(typeOf(ChatMessageSendReq{}), controller.ChatMessageSend)))
 
+	// codex routes
+	router.GET("/codex", auth(controller.Codex))
+
 	// daily quest routes
 	router.PUT("/daily-quest/:id/complete", auth(controller.DailyQuestComplete))
 
+	// idle upgrade routes
+	router.PUT("/idle-upgrade/:id/buy", auth(controller.IdleUpgradeBuy))
+
+	// item routes
+	router.GET("/items", auth(controller.ItemList))
+	router.PUT("/item/:id/equip", auth(body(typeOf(ItemEquipReq{}), controller.ItemEquip)))
+	router.PUT("/item/:id/unequip", auth(controller.ItemUnequip))
+
+	// prestige routes
+	router.PUT("/prestige", auth(controller.Prestige))
+
+	// roster routes
+	router.PUT("/roster/expand", auth(controller.RosterExpand))
+
 	// summon routes
+	router.GET("/summon/rates", controller.SummonRates)
+	router.GET("/summon/banners", auth(controller.SummonBanners))
+	router.GET("/summon/history", auth(controller.SummonHistory))
+	router.PUT("/summon/multi", auth(controller.SummonMulti))
+	router.GET("/summon/report", auth(admin(controller.SummonReport)))
 	router.PUT("/summon/unit", auth(controller.SummonUnit))
 
+	// team routes
+	router.GET("/teams", auth(controller.TeamList))
+	router.POST("/team", auth(body(typeOf(TeamReq{}), controller.TeamCreate)))
+	router.PUT("/team/:id", auth(body(typeOf(TeamReq{}), controller.TeamUpdate)))
+	router.DELETE("/team/:id", auth(controller.TeamDelete))
+	router.PUT("/team/:id/campaign-default", auth(controller.TeamSetCampaignDefault))
+
 	// unit routes
+	router.GET("/units", auth(controller.UnitList))
+	router.GET("/unit/:id", auth(controller.UnitDetail))
 	router.PUT("/unit/:id/toggle-lock", auth(controller.UnitToggleLock))
+	router.PUT("/unit/:id/level-up", auth(body(typeOf(UnitLevelUpReq{}), controller.UnitLevelUp)))
+	router.PUT("/unit/:id/evolve", auth(body(typeOf(UnitEvolveReq{}), controller.UnitEvolve)))
+	router.PUT("/units/lock", auth(body(typeOf(UnitBulkLockReq{}), controller.UnitBulkLock)))
+	router.PUT("/units/retire", auth(body(typeOf(UnitRetireReq{}), controller.UnitRetire)))
 
 	// user routes
 	router.POST("/user/sign-up", body(typeOf(SignUpReq{}), controller.SignUp))
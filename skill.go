@@ -0,0 +1,54 @@
+package main
+
+// A unit skill. Active skills are used in place of a basic attack whenever they are off cooldown.
+// Passive skills apply their effects once at the start of the battle and the effects last the whole battle.
+type Skill struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Type        int           `json:"type"`
+	Target      int           `json:"target"`
+	Damage      int           `json:"damage"`   // Percent of the user's atk dealt as damage to each target.
+	Heal        int           `json:"heal"`     // Percent of the user's atk restored as hp to each target.
+	Cooldown    int           `json:"cooldown"` // The skill can be used again this many rounds after being used.
+	Effects     []SkillEffect `json:"effects"`
+}
+
+// A buff or debuff applied to each target of a skill.
+type SkillEffect struct {
+	Stat     int `json:"stat"`
+	Amount   int `json:"amount"`   // Percent added to the stat, negative for debuffs.
+	Duration int `json:"duration"` // Rounds the effect lasts, 0 lasts the whole battle.
+}
+
+// Returns true if the skill targets the user's allies instead of the enemies.
+func (s Skill) TargetsAllies() bool {
+	return s.Target == SKILL_TARGET_SELF || s.Target == SKILL_TARGET_ALLY || s.Target == SKILL_TARGET_ALL_ALLIES
+}
+
+// Returns the units affected by the skill. Single target skills target the living unit with the lowest hp.
+func (s Skill) Targets(user *BattleUnit, allies []BattleUnit, enemies []BattleUnit) []*BattleUnit {
+	units := enemies
+	if s.TargetsAllies() {
+		units = allies
+	}
+
+	switch s.Target {
+	case SKILL_TARGET_SELF:
+		return []*BattleUnit{user}
+
+	case SKILL_TARGET_ALL_ALLIES, SKILL_TARGET_ALL_ENEMIES:
+		targets := make([]*BattleUnit, 0, len(units))
+		for i := range units {
+			if units[i].IsAlive() {
+				targets = append(targets, &units[i])
+			}
+		}
+		return targets
+
+	default:
+		if target := LowestHpTarget(units); target != nil {
+			return []*BattleUnit{target}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// The weight of rolling a rarity when summoning.
+type SummonRate struct {
+	Rarity int `json:"rarity"`
+	Weight int `json:"weight"`
+}
+
+func UnMarshalSummonRatesJson() ([]SummonRate, error) {
+	var data map[string][]SummonRate
+
+	err := json.Unmarshal([]byte(summonRatesJson), &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data["summonRates"], nil
+}
+
+// The chance of summoning a rarity and each template within it. Rates are in basis points, 10000 is 100%.
+type RarityRate struct {
+	Rarity    int            `json:"rarity"`
+	Rate      int            `json:"rate"`
+	Templates []TemplateRate `json:"templates"`
+}
+
+type TemplateRate struct {
+	Template int `json:"template"`
+	Rate     int `json:"rate"`
+}
+
+// Returns the IDs of the unit templates with the given rarity.
+func TemplatesByRarity(dc *DataCache, rarity int) []int {
+	templates := make([]int, 0)
+
+	for _, template := range dc.UnitTemplates {
+		if template.Rarity == rarity {
+			templates = append(templates, template.ID)
+		}
+	}
+
+	return templates
+}
+
+// Returns the summon rates of the rarities that have at least one template. Rarities without templates can't be rolled.
+func AvailableSummonRates(dc *DataCache) []SummonRate {
+	rates := make([]SummonRate, 0, len(dc.SummonRates))
+
+	for _, rate := range dc.SummonRates {
+		if len(TemplatesByRarity(dc, rate.Rarity)) > 0 {
+			rates = append(rates, rate)
+		}
+	}
+
+	return rates
+}
+
+// Returns the effective chance of summoning every rarity and template.
+func EffectiveSummonRates(dc *DataCache) []RarityRate {
+	rates := AvailableSummonRates(dc)
+	effective := make([]RarityRate, len(rates))
+
+	total := 0
+	for _, rate := range rates {
+		total += rate.Weight
+	}
+
+	for i, rate := range rates {
+		templates := TemplatesByRarity(dc, rate.Rarity)
+
+		effective[i] = RarityRate{
+			Rarity:    rate.Rarity,
+			Rate:      rate.Weight * 10000 / total,
+			Templates: make([]TemplateRate, len(templates)),
+		}
+
+		for j, template := range templates {
+			effective[i].Templates[j] = TemplateRate{
+				Template: template,
+				Rate:     rate.Weight * 10000 / total / len(templates),
+			}
+		}
+	}
+
+	return effective
+}
+
+// Returns the currency from the currency query parameter, gems are used if the parameter is missing.
+func ParseSummonCurrency(params url.Values) (string, error) {
+	currency := params.Get("currency")
+
+	switch currency {
+	case "":
+		return SUMMON_CURRENCY_GEMS, nil
+
+	case SUMMON_CURRENCY_GEMS, SUMMON_CURRENCY_TICKETS, SUMMON_CURRENCY_FREE:
+		return currency, nil
+
+	default:
+		return "", fmt.Errorf("currency should be %v, %v or %v", SUMMON_CURRENCY_GEMS, SUMMON_CURRENCY_TICKETS, SUMMON_CURRENCY_FREE)
+	}
+}
+
+// Returns the time the user's next free summon becomes available.
+// Users that have never used a free summon could use one as soon as they signed up.
+func NextFreeSummonAt(freeSummonAt *time.Time, createdAt time.Time) time.Time {
+	if freeSummonAt == nil {
+		return createdAt
+	}
+
+	return freeSummonAt.Add(FREE_SUMMON_INTERVAL)
+}
+
+// Will find the time the user's next free summon becomes available.
+func FindNextFreeSummon(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) (time.Time, error) {
+	var freeSummonAt *time.Time
+	var createdAt time.Time
+
+	query := "SELECT free_summon_at, created_at FROM users WHERE id = $1"
+	if err := db.QueryRow(ctx, query, userId).Scan(&freeSummonAt, &createdAt); err != nil {
+		return time.Time{}, fmt.Errorf("fail to query users row: %w", err)
+	}
+
+	return NextFreeSummonAt(freeSummonAt, createdAt), nil
+}
+
+// Will find the time the user's next free summon becomes available. The user row is locked.
+func FindNextFreeSummonLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID) (time.Time, error) {
+	var freeSummonAt *time.Time
+	var createdAt time.Time
+
+	query := "SELECT free_summon_at, created_at FROM users WHERE id = $1 FOR UPDATE"
+	if err := tx.QueryRow(ctx, query, userId).Scan(&freeSummonAt, &createdAt); err != nil {
+		return time.Time{}, fmt.Errorf("fail to query users row: %w", err)
+	}
+
+	return NextFreeSummonAt(freeSummonAt, createdAt), nil
+}
+
+// Will use the user's free summon. Returns the time the next free summon becomes available.
+func UseFreeSummon(ctx context.Context, tx pgx.Tx, userId uuid.UUID) (time.Time, error) {
+	now := time.Now()
+
+	if _, err := tx.Exec(ctx, "UPDATE users SET free_summon_at = $1 WHERE id = $2", now, userId); err != nil {
+		return time.Time{}, fmt.Errorf("fail to update users row: %w", err)
+	}
+
+	return NextFreeSummonAt(&now, now), nil
+}
+
+// Game data describing a multi summon. The discount is a percent taken off the cost of summoning each unit separately.
+type MultiSummon struct {
+	Count            int `json:"count"`
+	Discount         int `json:"discount"`
+	GuaranteedRarity int `json:"guaranteedRarity"` // At least one unit will have this rarity or higher.
+}
+
+func UnMarshalMultiSummonJson() (MultiSummon, error) {
+	var data map[string]MultiSummon
+
+	err := json.Unmarshal([]byte(multiSummonJson), &data)
+	if err != nil {
+		return MultiSummon{}, err
+	}
+
+	return data["multiSummon"], nil
+}
+
+// Returns the gem cost of the multi summon.
+func (m MultiSummon) Cost() int {
+	return UNIT_SUMMON_COST * m.Count * (100 - m.Discount) / 100
+}
+
+// Will summon units on the banner and insert them. The pity, codex and summon history are updated after every unit
+// and codex discovery rewards are applied. If no earlier unit has the guaranteed rarity, the last unit is rolled
+// with the guaranteed rarity as the minimum. The cost is the transaction that paid for the units, it is split
+// between the units in the summon history. The rolls use a fork of the rng and its seed is recorded in the
+// summon history. Returns the units and the discovery rewards.
+func SummonUnits(ctx context.Context, tx pgx.Tx, dc *DataCache, rng Rng, userId uuid.UUID, banner Banner, pity *BannerPity,
+	count int, guaranteed int, cost Transaction) ([]Unit, []Transaction, error) {
+
+	rng, seed := rng.Fork()
+
+	units := make([]Unit, 0, count)
+	rewards := make([]Transaction, 0)
+	highest := RARITY_COMMON
+
+	for i := 0; i < count; i++ {
+		minRarity := RARITY_COMMON
+		if i == count-1 && highest < guaranteed {
+			minRarity = guaranteed
+		}
+
+		template, rarity := banner.Roll(dc, rng, pity.Count, minRarity)
+		if rarity > highest {
+			highest = rarity
+		}
+
+		if err := pity.Update(ctx, tx, userId, banner, rarity); err != nil {
+			return units, rewards, err
+		}
+
+		unit := CreateUnit(template)
+		unit.Stats = CalcUnitStats(dc, unit)
+
+		if err := InsertUnit(ctx, tx, userId, unit); err != nil {
+			return units, rewards, err
+		}
+
+		// the first unit takes the remainder so the history adds up to the cost
+		record := SummonRecord{
+			BannerId:  banner.Id,
+			Template:  template,
+			Rarity:    rarity,
+			CostType:  cost.Type,
+			Seed:      seed,
+			Cost:      -cost.Amount / count,
+			CreatedAt: time.Now(),
+		}
+
+		if i == 0 {
+			record.Cost += -cost.Amount % count
+		}
+
+		if err := InsertSummonRecord(ctx, tx, userId, record); err != nil {
+			return units, rewards, err
+		}
+
+		discovered, err := UpdateCodex(ctx, tx, userId, unit)
+		if err != nil {
+			return units, rewards, err
+		}
+
+		if discovered {
+			reward := CodexDiscoveryReward()
+
+			if err := reward.Apply(ctx, tx, userId); err != nil {
+				return units, rewards, err
+			}
+
+			rewards = append(rewards, reward)
+		}
+
+		units = append(units, unit)
+	}
+
+	return units, MergeTransactions(rewards), nil
+}
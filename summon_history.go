@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// A single summon in the user's summon history. The cost is the amount spent on this unit and the cost type
+// is the transaction type that was spent. The seed of the rolls is kept so the summon can be audited.
+type SummonRecord struct {
+	Id        int       `json:"id"`
+	BannerId  int       `json:"bannerId"`
+	Template  int       `json:"template"`
+	Rarity    int       `json:"rarity"`
+	CostType  int       `json:"costType"`
+	Cost      int       `json:"cost"`
+	Seed      int64     `json:"seed"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Compares the observed chance of summoning a rarity with the configured chance. Rates are in basis points.
+type SummonRateReport struct {
+	Rarity         int `json:"rarity"`
+	Count          int `json:"count"`
+	ConfiguredRate int `json:"configuredRate"`
+	ObservedRate   int `json:"observedRate"`
+}
+
+// Will insert a record of the summon into the user's summon history.
+func InsertSummonRecord(ctx context.Context, tx pgx.Tx, userId uuid.UUID, record SummonRecord) error {
+	query := `INSERT INTO summon_history (user_id, banner_id, template, rarity, cost_type, cost, seed, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := tx.Exec(ctx, query, userId, record.BannerId, record.Template, record.Rarity, record.CostType, record.Cost,
+		record.Seed, record.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("fail to insert summon_history row: %w", err)
+	}
+
+	return nil
+}
+
+// Returns the summon history cursor from the cursor query parameter. Nil is returned if the parameter is missing.
+// The cursor is the ID of the last record of the previous page.
+func ParseSummonHistoryCursor(params url.Values) (*int, error) {
+	param := params.Get("cursor")
+	if param == "" {
+		return nil, nil
+	}
+
+	cursor, err := strconv.Atoi(param)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	return &cursor, nil
+}
+
+// Will find a page of the user's summon history, newest summons first.
+// The next cursor is empty if there are no more records.
+func FindSummonHistoryPage(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID, cursor *int) ([]SummonRecord, string, error) {
+	records := make([]SummonRecord, 0)
+
+	query := `SELECT id, banner_id, template, rarity, cost_type, cost, seed, created_at FROM summon_history
+			  WHERE (user_id = $1 AND ($2::integer IS NULL OR id < $2)) ORDER BY id DESC LIMIT $3`
+
+	rows, err := db.Query(ctx, query, userId, cursor, SUMMON_HISTORY_PAGE_SIZE+1)
+	if err != nil {
+		return records, "", fmt.Errorf("fail to query summon_history table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record SummonRecord
+
+		err := rows.Scan(&record.Id, &record.BannerId, &record.Template, &record.Rarity, &record.CostType, &record.Cost, &record.Seed, &record.CreatedAt)
+		if err != nil {
+			return records, "", fmt.Errorf("fail to scan into summon record: %w", err)
+		}
+
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return records, "", err
+	}
+
+	if len(records) <= SUMMON_HISTORY_PAGE_SIZE {
+		return records, "", nil
+	}
+
+	records = records[:SUMMON_HISTORY_PAGE_SIZE]
+
+	return records, strconv.Itoa(records[len(records)-1].Id), nil
+}
+
+// Returns the observed rate of every rarity across all summons of all users compared with the configured rate.
+// Pity and multi summon guarantees raise the observed rates of the higher rarities above the configured rates.
+func FindSummonRateReport(ctx context.Context, db *pgxpool.Pool, dc *DataCache) ([]SummonRateReport, int, error) {
+	rates := EffectiveSummonRates(dc)
+	reports := make([]SummonRateReport, len(rates))
+	index := make(map[int]int)
+
+	for i, rate := range rates {
+		reports[i] = SummonRateReport{Rarity: rate.Rarity, ConfiguredRate: rate.Rate}
+		index[rate.Rarity] = i
+	}
+
+	rows, err := db.Query(ctx, "SELECT rarity, COUNT(*) FROM summon_history GROUP BY rarity")
+	if err != nil {
+		return reports, 0, fmt.Errorf("fail to query summon_history table: %w", err)
+	}
+	defer rows.Close()
+
+	total := 0
+
+	for rows.Next() {
+		var rarity, count int
+
+		if err := rows.Scan(&rarity, &count); err != nil {
+			return reports, 0, fmt.Errorf("fail to scan summon_history count: %w", err)
+		}
+
+		total += count
+
+		// rarities that are no longer configured are still counted in the total
+		if i, ok := index[rarity]; ok {
+			reports[i].Count = count
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return reports, 0, err
+	}
+
+	if total > 0 {
+		for i := range reports {
+			reports[i].ObservedRate = reports[i].Count * 10000 / total
+		}
+	}
+
+	return reports, total, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// A named team preset. Units referenced by a team cannot be retired or used as fodder.
+type Team struct {
+	Id                int        `json:"id"`
+	UserId            uuid.UUID  `json:"-"`
+	Name              string     `json:"name"`
+	IsCampaignDefault bool       `json:"isCampaignDefault"`
+	Units             []TeamSlot `json:"units"`
+}
+
+// The position of a unit in a team.
+type TeamSlot struct {
+	Slot   int       `json:"slot"`
+	UnitId uuid.UUID `json:"unitId"`
+}
+
+// Returns the IDs of the units in the team ordered by slot.
+func (t *Team) UnitIds() []uuid.UUID {
+	unitIds := make([]uuid.UUID, len(t.Units))
+	for i, slot := range t.Units {
+		unitIds[i] = slot.UnitId
+	}
+
+	return unitIds
+}
+
+// Will insert the team and its units. The team ID will be set.
+func InsertTeam(ctx context.Context, tx pgx.Tx, team *Team) error {
+	query := "INSERT INTO teams (user_id, name) VALUES ($1, $2) RETURNING id"
+
+	err := tx.QueryRow(ctx, query, team.UserId, team.Name).Scan(&team.Id)
+	if err != nil {
+		return fmt.Errorf("fail to insert teams row: %w", err)
+	}
+
+	return InsertTeamUnits(ctx, tx, team)
+}
+
+func InsertTeamUnits(ctx context.Context, tx pgx.Tx, team *Team) error {
+	for _, slot := range team.Units {
+		query := "INSERT INTO team_units (team_id, unit_id, slot) VALUES ($1, $2, $3)"
+
+		if _, err := tx.Exec(ctx, query, team.Id, slot.UnitId, slot.Slot); err != nil {
+			return fmt.Errorf("fail to insert team_units row: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Will update the team's name and replace its units.
+func (t *Team) Update(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, "UPDATE teams SET name = $1 WHERE id = $2", t.Name, t.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update teams row: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM team_units WHERE team_id = $1", t.Id)
+	if err != nil {
+		return fmt.Errorf("fail to delete team_units rows: %w", err)
+	}
+
+	return InsertTeamUnits(ctx, tx, t)
+}
+
+// Will make the team the user's default campaign team.
+func (t *Team) SetCampaignDefault(ctx context.Context, tx pgx.Tx) error {
+	query := "UPDATE teams SET is_campaign_default = (id = $1) WHERE user_id = $2"
+
+	_, err := tx.Exec(ctx, query, t.Id, t.UserId)
+	if err != nil {
+		return fmt.Errorf("fail to update teams rows: %w", err)
+	}
+
+	t.IsCampaignDefault = true
+
+	return nil
+}
+
+// Will delete the team owned by the user. Returns false if the team wasn't found.
+func DeleteTeam(ctx context.Context, tx pgx.Tx, userId uuid.UUID, teamId int) (bool, error) {
+	cmdTag, err := tx.Exec(ctx, "DELETE FROM teams WHERE (id = $1 AND user_id = $2)", teamId, userId)
+	if err != nil {
+		return false, fmt.Errorf("fail to delete teams row: %w", err)
+	}
+
+	return cmdTag.RowsAffected() > 0, nil
+}
+
+// Will find a team owned by the user for update. The team's units are not included.
+func FindTeamLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, teamId int) (Team, error) {
+	team := Team{Id: teamId, UserId: userId}
+
+	query := "SELECT name, is_campaign_default FROM teams WHERE (id = $1 AND user_id = $2) FOR UPDATE"
+	err := tx.QueryRow(ctx, query, teamId, userId).Scan(&team.Name, &team.IsCampaignDefault)
+	if err != nil {
+		return team, fmt.Errorf("fail to query teams row: %w", err)
+	}
+
+	return team, nil
+}
+
+// Returns the number of teams the user has.
+func CountTeams(ctx context.Context, tx pgx.Tx, userId uuid.UUID) (int, error) {
+	var count int
+
+	err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM teams WHERE user_id = $1", userId).Scan(&count)
+	if err != nil {
+		return count, fmt.Errorf("fail to count teams: %w", err)
+	}
+
+	return count, nil
+}
+
+// Find all teams belonging to the user along with their units.
+func FindTeams(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) ([]Team, error) {
+	teams := make([]Team, 0)
+	index := make(map[int]int)
+
+	query := "SELECT id, name, is_campaign_default FROM teams WHERE user_id = $1 ORDER BY id"
+	rows, err := db.Query(ctx, query, userId)
+	if err != nil {
+		return teams, fmt.Errorf("fail to query teams table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		team := Team{UserId: userId, Units: make([]TeamSlot, 0)}
+
+		if err := rows.Scan(&team.Id, &team.Name, &team.IsCampaignDefault); err != nil {
+			return teams, fmt.Errorf("fail to scan teams row: %w", err)
+		}
+
+		index[team.Id] = len(teams)
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return teams, err
+	}
+
+	query = `SELECT team_units.team_id, team_units.slot, team_units.unit_id FROM team_units
+			 INNER JOIN teams ON teams.id = team_units.team_id
+			 WHERE teams.user_id = $1 ORDER BY team_units.slot`
+
+	slotRows, err := db.Query(ctx, query, userId)
+	if err != nil {
+		return teams, fmt.Errorf("fail to query team_units table: %w", err)
+	}
+	defer slotRows.Close()
+
+	for slotRows.Next() {
+		var teamId int
+		var slot TeamSlot
+
+		if err := slotRows.Scan(&teamId, &slot.Slot, &slot.UnitId); err != nil {
+			return teams, fmt.Errorf("fail to scan team_units row: %w", err)
+		}
+
+		if i, ok := index[teamId]; ok {
+			teams[i].Units = append(teams[i].Units, slot)
+		}
+	}
+
+	return teams, slotRows.Err()
+}
+
+// Returns the unit IDs of the user's default campaign team ordered by slot. Empty if there is no default team.
+func FindCampaignTeamUnitIds(ctx context.Context, tx pgx.Tx, userId uuid.UUID) ([]uuid.UUID, error) {
+	unitIds := make([]uuid.UUID, 0)
+
+	query := `SELECT team_units.unit_id FROM team_units
+			  INNER JOIN teams ON teams.id = team_units.team_id
+			  WHERE (teams.user_id = $1 AND teams.is_campaign_default) ORDER BY team_units.slot`
+
+	rows, err := tx.Query(ctx, query, userId)
+	if err != nil {
+		return unitIds, fmt.Errorf("fail to query team_units table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var unitId uuid.UUID
+
+		if err := rows.Scan(&unitId); err != nil {
+			return unitIds, fmt.Errorf("fail to scan team_units row: %w", err)
+		}
+
+		unitIds = append(unitIds, unitId)
+	}
+
+	return unitIds, rows.Err()
+}
@@ -10,8 +10,9 @@ import (
 
 // Represents a modification of a integer value such as gold or user exp.
 type Transaction struct {
-	Type   int `json:"type"`
-	Amount int `json:"amount"`
+	Type         int `json:"type"`
+	Amount       int `json:"amount"`
+	ItemTemplate int `json:"itemTemplate,omitempty"` // Only used by item transactions.
 }
 
 // Will apply the transaction to the correct table row.
@@ -20,9 +21,50 @@ func (r Transaction) Apply(ctx context.Context, tx pgx.Tx, userId uuid.UUID) err
 	case TRANSACTION_GEMS:
 		return IncResource(ctx, tx, userId, RESOURCE_GEMS, r.Amount)
 
+	case TRANSACTION_GOLD:
+		return IncResource(ctx, tx, userId, RESOURCE_GOLD, r.Amount)
+
+	case TRANSACTION_EXP_STONES:
+		return IncResource(ctx, tx, userId, RESOURCE_EXP_STONE, r.Amount)
+
+	case TRANSACTION_EVO_STONES:
+		return IncResource(ctx, tx, userId, RESOURCE_EVO_STONE, r.Amount)
+
+	case TRANSACTION_USER_EXP:
+		return IncUserExp(ctx, tx, userId, r.Amount)
+
+	case TRANSACTION_ITEM:
+		return InsertItems(ctx, tx, userId, r.ItemTemplate, r.Amount)
+
+	case TRANSACTION_SUMMON_TICKETS:
+		return IncResource(ctx, tx, userId, RESOURCE_SUMMON_TICKET, r.Amount)
+
+	case TRANSACTION_FREE_SUMMON:
+		return nil
+
 	default:
 		log.Fatalf("failed to apply transaction of type %v, not handled in switch statement\n", r.Type)
 	}
 
 	return nil
 }
+
+// Will combine transactions of the same type into a single transaction. Item transactions are only combined
+// if they have the same item template. The order of first appearance is kept.
+func MergeTransactions(transactions []Transaction) []Transaction {
+	merged := make([]Transaction, 0, len(transactions))
+	index := make(map[Transaction]int)
+
+	for _, transaction := range transactions {
+		key := Transaction{Type: transaction.Type, ItemTemplate: transaction.ItemTemplate}
+
+		if i, ok := index[key]; ok {
+			merged[i].Amount += transaction.Amount
+		} else {
+			index[key] = len(merged)
+			merged = append(merged, transaction)
+		}
+	}
+
+	return merged
+}
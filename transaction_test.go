@@ -0,0 +1,35 @@
+package main_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/cdrpl/idlemon-server"
+)
+
+func TestMergeTransactions(t *testing.T) {
+	transactions := []Transaction{
+		{Type: TRANSACTION_GOLD, Amount: 5},
+		{Type: TRANSACTION_ITEM, Amount: 1, ItemTemplate: 1},
+		{Type: TRANSACTION_GEMS, Amount: 2},
+		{Type: TRANSACTION_ITEM, Amount: 1, ItemTemplate: 2},
+		{Type: TRANSACTION_GOLD, Amount: 3},
+		{Type: TRANSACTION_ITEM, Amount: 2, ItemTemplate: 1},
+	}
+
+	// items merge by template, other transactions by type, in order of first appearance
+	expect := []Transaction{
+		{Type: TRANSACTION_GOLD, Amount: 8},
+		{Type: TRANSACTION_ITEM, Amount: 3, ItemTemplate: 1},
+		{Type: TRANSACTION_GEMS, Amount: 2},
+		{Type: TRANSACTION_ITEM, Amount: 1, ItemTemplate: 2},
+	}
+
+	if merged := MergeTransactions(transactions); !reflect.DeepEqual(merged, expect) {
+		t.Fatalf("expect merged transactions %+v, receive: %+v", expect, merged)
+	}
+
+	if merged := MergeTransactions(nil); len(merged) != 0 {
+		t.Fatalf("expect no transactions, receive: %+v", merged)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+)
+
+// Unit types deal bonus damage to the types they have an advantage over.
+// Attacks against a type that has the advantage can miss.
+type TypeAdvantage struct {
+	DamageBonus int                 `json:"damageBonus"` // Percent of extra damage dealt with the advantage.
+	MissChance  int                 `json:"missChance"`  // Percent chance to miss when attacking with the disadvantage.
+	Advantages  []TypeAdvantagePair `json:"advantages"`
+}
+
+// The attacker type has the advantage over the defender type.
+type TypeAdvantagePair struct {
+	Attacker int `json:"attacker"`
+	Defender int `json:"defender"`
+}
+
+func UnMarshalTypeAdvantageJson() (TypeAdvantage, error) {
+	var data map[string]TypeAdvantage
+
+	err := json.Unmarshal([]byte(typeAdvantagesJson), &data)
+	if err != nil {
+		return TypeAdvantage{}, err
+	}
+
+	return data["typeAdvantage"], nil
+}
+
+// Returns true if the attacker type has the advantage over the defender type.
+func (t TypeAdvantage) HasAdvantage(attacker int, defender int) bool {
+	for _, pair := range t.Advantages {
+		if pair.Attacker == attacker && pair.Defender == defender {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Returns the percent of damage dealt by the attacker type to the defender type.
+func (t TypeAdvantage) DamagePercent(attacker int, defender int) int {
+	if t.HasAdvantage(attacker, defender) {
+		return 100 + t.DamageBonus
+	}
+
+	return 100
+}
+
+// Returns the percent chance that an attack misses. Types that counter each other never miss.
+func (t TypeAdvantage) AttackMissChance(attacker int, defender int) int {
+	if t.HasAdvantage(defender, attacker) && !t.HasAdvantage(attacker, defender) {
+		return t.MissChance
+	}
+
+	return 0
+}
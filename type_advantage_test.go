@@ -0,0 +1,54 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/cdrpl/idlemon-server"
+)
+
+func TestTypeAdvantage(t *testing.T) {
+	adv := idlemonServer.DataCache.TypeAdvantage
+
+	tests := []struct {
+		name          string
+		attacker      int
+		defender      int
+		damagePercent int
+		missChance    int
+	}{
+		{"advantage", UNIT_TYPE_FOREST, UNIT_TYPE_ABYSS, 100 + adv.DamageBonus, 0},
+		{"disadvantage", UNIT_TYPE_ABYSS, UNIT_TYPE_FOREST, 100, adv.MissChance},
+		{"advantage over forest", UNIT_TYPE_FORTRESS, UNIT_TYPE_FOREST, 100 + adv.DamageBonus, 0},
+		{"disadvantage against fortress", UNIT_TYPE_FOREST, UNIT_TYPE_FORTRESS, 100, adv.MissChance},
+		{"light counters dark", UNIT_TYPE_LIGHT, UNIT_TYPE_DARK, 100 + adv.DamageBonus, 0},
+		{"dark counters light", UNIT_TYPE_DARK, UNIT_TYPE_LIGHT, 100 + adv.DamageBonus, 0},
+		{"same type", UNIT_TYPE_SHADOW, UNIT_TYPE_SHADOW, 100, 0},
+		{"unrelated types", UNIT_TYPE_FOREST, UNIT_TYPE_LIGHT, 100, 0},
+	}
+
+	for _, test := range tests {
+		if percent := adv.DamagePercent(test.attacker, test.defender); percent != test.damagePercent {
+			t.Errorf("%v: expect damage percent %v, receive: %v", test.name, test.damagePercent, percent)
+		}
+
+		if chance := adv.AttackMissChance(test.attacker, test.defender); chance != test.missChance {
+			t.Errorf("%v: expect miss chance %v, receive: %v", test.name, test.missChance, chance)
+		}
+	}
+}
+
+func TestTypeAdvantageDamage(t *testing.T) {
+	adv := idlemonServer.DataCache.TypeAdvantage
+	rng := CreateSeededRng(1)
+
+	attacker := BattleUnit{Type: UNIT_TYPE_LIGHT, Hp: 1000, MaxHp: 1000, Atk: 100}
+	target := BattleUnit{Type: UNIT_TYPE_DARK, Hp: 1000, MaxHp: 1000, Def: 20}
+
+	// light and dark counter each other so the attack can't miss and deals the bonus damage
+	attacker.Damage(adv, rng, &target, 100)
+
+	expect := 1000 - (100-20/2)*(100+adv.DamageBonus)/100
+	if target.Hp != expect {
+		t.Fatalf("expect target hp %v, receive: %v", expect, target.Hp)
+	}
+}
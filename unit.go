@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -15,6 +16,43 @@ type Unit struct {
 	Level    int       `json:"level"`
 	Stars    int       `json:"stars"`
 	IsLocked bool      `json:"isLocked"`
+	IsBusy   bool      `json:"isBusy"` // Busy units are away on a bounty.
+	InTeam   bool      `json:"inTeam"` // Units in a team cannot be retired or used as fodder.
+	Items    []int     `json:"items"`  // Templates of the equipped items.
+	Stats    UnitStats `json:"stats"`
+}
+
+// The columns selected when querying units, scan them using Unit.ScanTargets.
+const UNIT_COLUMNS = "id, template, level, stars, is_locked, bounty_id IS NOT NULL, EXISTS (SELECT 1 FROM team_units WHERE team_units.unit_id = units.id), " +
+	"ARRAY(SELECT template FROM items WHERE items.unit_id = units.id)"
+
+// Returns the scan destinations matching UNIT_COLUMNS.
+func (u *Unit) ScanTargets() []interface{} {
+	return []interface{}{&u.Id, &u.Template, &u.Level, &u.Stars, &u.IsLocked, &u.IsBusy, &u.InTeam, &u.Items}
+}
+
+// The outcome of a bulk operation on a single unit. The message explains why the operation failed.
+type UnitResult struct {
+	UnitId  uuid.UUID `json:"unitId"`
+	Success bool      `json:"success"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Returns an error describing why the unit cannot be retired.
+func (u *Unit) CheckRetire() error {
+	if u.IsLocked {
+		return errors.New("unit is locked")
+	}
+
+	if u.IsBusy {
+		return errors.New("unit is busy")
+	}
+
+	if u.InTeam {
+		return errors.New("unit is in a team")
+	}
+
+	return nil
 }
 
 // Create a unit with the given template ID.
@@ -24,13 +62,18 @@ func CreateUnit(templateID int) Unit {
 		Template: templateID,
 		Level:    1,
 		Stars:    1,
+		Items:    make([]int, 0),
 	}
 }
 
-// Will return a random 1 star unit at level 1.
-func RandUnit(dc *DataCache) Unit {
-	template := RandUnitTemplateID(dc)
-	return CreateUnit(template)
+// Returns the template of every unit.
+func UnitTemplates(units []Unit) []int {
+	templates := make([]int, len(units))
+	for i, unit := range units {
+		templates[i] = unit.Template
+	}
+
+	return templates
 }
 
 // Will insert a unit into the database and return the unit ID.
@@ -41,25 +84,123 @@ func InsertUnit(ctx context.Context, tx pgx.Tx, userId uuid.UUID, unit Unit) err
 	return err
 }
 
-func FindUnits(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) ([]Unit, error) {
+// Find all units belonging to a user. The stats of each unit are calculated.
+func FindUnits(ctx context.Context, db *pgxpool.Pool, dc *DataCache, userId uuid.UUID) ([]Unit, error) {
 	units := make([]Unit, 0)
 
-	query := "SELECT id, template, level, stars, is_locked FROM units WHERE user_id = $1"
+	query := "SELECT " + UNIT_COLUMNS + " FROM units WHERE user_id = $1"
 	rows, err := db.Query(ctx, query, userId)
 	if err != nil {
 		return units, fmt.Errorf("fail to query units table: %w", err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var unit Unit
 
-		err := rows.Scan(&unit.Id, &unit.Template, &unit.Level, &unit.Stars, &unit.IsLocked)
+		err := rows.Scan(unit.ScanTargets()...)
 		if err != nil {
 			return units, fmt.Errorf("fail to scan into unit: %w", err)
 		}
 
+		unit.Stats = CalcUnitStats(dc, unit)
 		units = append(units, unit)
 	}
 
-	return units, nil
+	return units, rows.Err()
+}
+
+// Find a unit belonging to the user. The stats of the unit are calculated.
+func FindUnit(ctx context.Context, db *pgxpool.Pool, dc *DataCache, userId uuid.UUID, unitId uuid.UUID) (Unit, error) {
+	var unit Unit
+
+	query := "SELECT " + UNIT_COLUMNS + " FROM units WHERE (id = $1 AND user_id = $2)"
+	err := db.QueryRow(ctx, query, unitId, userId).Scan(unit.ScanTargets()...)
+	if err != nil {
+		return unit, fmt.Errorf("fail to query units table: %w", err)
+	}
+
+	unit.Stats = CalcUnitStats(dc, unit)
+
+	return unit, nil
+}
+
+// Will find the units with the given IDs for update. Units not owned by the user are excluded.
+func FindUnitsLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, unitIds []uuid.UUID) ([]Unit, error) {
+	units := make([]Unit, 0)
+
+	query := "SELECT " + UNIT_COLUMNS + " FROM units WHERE (user_id = $1 AND id = ANY($2)) FOR UPDATE"
+	rows, err := tx.Query(ctx, query, userId, unitIds)
+	if err != nil {
+		return units, fmt.Errorf("fail to query units table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var unit Unit
+
+		err := rows.Scan(unit.ScanTargets()...)
+		if err != nil {
+			return units, fmt.Errorf("fail to scan into unit: %w", err)
+		}
+
+		units = append(units, unit)
+	}
+
+	return units, rows.Err()
+}
+
+// Will find a unit owned by the user for update.
+func FindUnitLock(ctx context.Context, tx pgx.Tx, userId uuid.UUID, unitId uuid.UUID) (Unit, error) {
+	var unit Unit
+
+	query := "SELECT " + UNIT_COLUMNS + " FROM units WHERE (id = $1 AND user_id = $2) FOR UPDATE"
+	err := tx.QueryRow(ctx, query, unitId, userId).Scan(unit.ScanTargets()...)
+	if err != nil {
+		return unit, fmt.Errorf("fail to query units table: %w", err)
+	}
+
+	return unit, nil
+}
+
+// Will lock or unlock the units owned by the user.
+func SetUnitsLocked(ctx context.Context, tx pgx.Tx, userId uuid.UUID, unitIds []uuid.UUID, locked bool) error {
+	query := "UPDATE units SET is_locked = $1 WHERE (user_id = $2 AND id = ANY($3))"
+
+	_, err := tx.Exec(ctx, query, locked, userId, unitIds)
+	if err != nil {
+		return fmt.Errorf("fail to update units rows: %w", err)
+	}
+
+	return nil
+}
+
+// Will set the unit's level in the database.
+func (u *Unit) UpdateLevel(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, "UPDATE units SET level = $1 WHERE id = $2", u.Level, u.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update units row: %w", err)
+	}
+
+	return nil
+}
+
+// Will set the unit's stars in the database.
+func (u *Unit) UpdateStars(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, "UPDATE units SET stars = $1 WHERE id = $2", u.Stars, u.Id)
+	if err != nil {
+		return fmt.Errorf("fail to update units row: %w", err)
+	}
+
+	return nil
+}
+
+// Will delete the units with the given IDs.
+func DeleteUnits(ctx context.Context, tx pgx.Tx, userId uuid.UUID, unitIds []uuid.UUID) error {
+	_, err := tx.Exec(ctx, "DELETE FROM units WHERE (user_id = $1 AND id = ANY($2))", userId, unitIds)
+	if err != nil {
+		return fmt.Errorf("fail to delete units rows: %w", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Game data describing how units are upgraded.
+type UnitProgression struct {
+	LevelCost   UnitLevelCost    `json:"levelCost"`
+	LevelCaps   []int            `json:"levelCaps"`   // The max level of a unit, indexed by stars - 1.
+	EvolveTiers []UnitEvolveTier `json:"evolveTiers"` // The requirements to evolve a unit, indexed by stars - 1.
+	Retire      UnitRetire       `json:"retire"`
+}
+
+// Retiring a unit refunds a percent of the gold and exp stones spent leveling it, plus evo stones for every star.
+type UnitRetire struct {
+	RefundPercent    int `json:"refundPercent"`
+	EvoStonesPerStar int `json:"evoStonesPerStar"`
+}
+
+// Fodder units must have the same template as the evolving unit if same template is true, otherwise the same type.
+type UnitEvolveTier struct {
+	FodderCount  int  `json:"fodderCount"`
+	FodderStars  int  `json:"fodderStars"` // Fodder units must have at least this many stars.
+	SameTemplate bool `json:"sameTemplate"`
+	EvoStones    int  `json:"evoStones"`
+}
+
+// The cost of a level up increases by the growth values every level.
+type UnitLevelCost struct {
+	BaseGold        int `json:"baseGold"`
+	GoldGrowth      int `json:"goldGrowth"`
+	BaseExpStones   int `json:"baseExpStones"`
+	ExpStonesGrowth int `json:"expStonesGrowth"`
+}
+
+func UnMarshalUnitProgressionJson() (UnitProgression, error) {
+	var data UnitProgression
+
+	err := json.Unmarshal([]byte(unitProgressionJson), &data)
+
+	return data, err
+}
+
+// Returns the max level of a unit with the given stars.
+func (p UnitProgression) LevelCap(stars int) int {
+	if stars < 1 {
+		stars = 1
+	}
+
+	if stars > len(p.LevelCaps) {
+		stars = len(p.LevelCaps)
+	}
+
+	return p.LevelCaps[stars-1]
+}
+
+// Returns the gold and exp stones required to raise a unit from the given level by the number of levels.
+func (p UnitProgression) LevelUpCost(level int, levels int) (int, int) {
+	gold, expStones := 0, 0
+
+	for l := level; l < level+levels; l++ {
+		gold += p.LevelCost.BaseGold + (l-1)*p.LevelCost.GoldGrowth
+		expStones += p.LevelCost.BaseExpStones + (l-1)*p.LevelCost.ExpStonesGrowth
+	}
+
+	return gold, expStones
+}
+
+// Returns the max stars a unit can be evolved to.
+func (p UnitProgression) MaxStars() int {
+	return len(p.EvolveTiers) + 1
+}
+
+// Returns the evolve tier for a unit with the given stars. The bool will be false if the unit is at max stars.
+func (p UnitProgression) EvolveTier(stars int) (UnitEvolveTier, bool) {
+	if stars < 1 || stars > len(p.EvolveTiers) {
+		return UnitEvolveTier{}, false
+	}
+
+	return p.EvolveTiers[stars-1], true
+}
+
+// Returns an error describing why the fodder unit cannot be consumed to evolve the unit.
+func (t UnitEvolveTier) CheckFodder(dc *DataCache, unit Unit, fodder Unit) error {
+	if fodder.IsLocked {
+		return fmt.Errorf("unit %v is locked", fodder.Id)
+	}
+
+	if fodder.IsBusy {
+		return fmt.Errorf("unit %v is busy", fodder.Id)
+	}
+
+	if fodder.InTeam {
+		return fmt.Errorf("unit %v is in a team", fodder.Id)
+	}
+
+	if fodder.Stars < t.FodderStars {
+		return fmt.Errorf("unit %v must have at least %v stars", fodder.Id, t.FodderStars)
+	}
+
+	if t.SameTemplate {
+		if fodder.Template != unit.Template {
+			return fmt.Errorf("unit %v must have the same template", fodder.Id)
+		}
+	} else {
+		template, _ := FindUnitTemplate(dc, unit.Template)
+		fodderTemplate, _ := FindUnitTemplate(dc, fodder.Template)
+
+		if fodderTemplate.TypeID != template.TypeID {
+			return fmt.Errorf("unit %v must have the same type", fodder.Id)
+		}
+	}
+
+	return nil
+}
+
+// Returns the resources refunded for retiring the unit.
+func (p UnitProgression) RetireRefund(unit Unit) []Transaction {
+	gold, expStones := p.LevelUpCost(1, unit.Level-1)
+
+	return []Transaction{
+		{Type: TRANSACTION_GOLD, Amount: gold * p.Retire.RefundPercent / 100},
+		{Type: TRANSACTION_EXP_STONES, Amount: expStones * p.Retire.RefundPercent / 100},
+		{Type: TRANSACTION_EVO_STONES, Amount: unit.Stars * p.Retire.EvoStonesPerStar},
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Filters, sorting and the page cursor used when listing a user's units. Nil filters are ignored.
+// Units are sorted in descending order with the unit ID breaking ties.
+type UnitQuery struct {
+	Template *int
+	Type     *int
+	Stars    *int
+	Locked   *bool
+	Sort     string
+	Cursor   *UnitCursor
+}
+
+// Points to the last unit of the previous page.
+type UnitCursor struct {
+	Value int // The sort value of the unit.
+	Id    uuid.UUID
+}
+
+func (c UnitCursor) String() string {
+	return fmt.Sprintf("%v_%v", c.Value, c.Id)
+}
+
+func ParseUnitCursor(cursor string) (UnitCursor, error) {
+	parts := strings.Split(cursor, "_")
+	if len(parts) != 2 {
+		return UnitCursor{}, errors.New("invalid cursor")
+	}
+
+	value, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return UnitCursor{}, errors.New("invalid cursor")
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return UnitCursor{}, errors.New("invalid cursor")
+	}
+
+	return UnitCursor{Value: value, Id: id}, nil
+}
+
+// Will create a unit query from the URL query parameters. The error message is safe to send to the client.
+func ParseUnitQuery(params url.Values) (UnitQuery, error) {
+	query := UnitQuery{Sort: UNIT_SORT_LEVEL}
+
+	ints := map[string]**int{"template": &query.Template, "type": &query.Type, "stars": &query.Stars}
+
+	for name, dest := range ints {
+		if param := params.Get(name); param != "" {
+			value, err := strconv.Atoi(param)
+			if err != nil {
+				return query, fmt.Errorf("%v should be an integer", name)
+			}
+
+			*dest = &value
+		}
+	}
+
+	if param := params.Get("locked"); param != "" {
+		locked, err := strconv.ParseBool(param)
+		if err != nil {
+			return query, errors.New("locked should be a boolean")
+		}
+
+		query.Locked = &locked
+	}
+
+	if param := params.Get("sort"); param != "" {
+		if param != UNIT_SORT_LEVEL && param != UNIT_SORT_STARS && param != UNIT_SORT_POWER {
+			return query, fmt.Errorf("sort should be %v, %v or %v", UNIT_SORT_LEVEL, UNIT_SORT_STARS, UNIT_SORT_POWER)
+		}
+
+		query.Sort = param
+	}
+
+	if param := params.Get("cursor"); param != "" {
+		cursor, err := ParseUnitCursor(param)
+		if err != nil {
+			return query, err
+		}
+
+		query.Cursor = &cursor
+	}
+
+	return query, nil
+}
+
+// Returns the value the unit is sorted by.
+func (q UnitQuery) SortValue(unit Unit) int {
+	switch q.Sort {
+	case UNIT_SORT_STARS:
+		return unit.Stars
+
+	case UNIT_SORT_POWER:
+		return unit.Stats.Power
+
+	default:
+		return unit.Level
+	}
+}
+
+// Returns true if the unit comes after the cursor.
+func (q UnitQuery) IsAfterCursor(unit Unit) bool {
+	if q.Cursor == nil {
+		return true
+	}
+
+	value := q.SortValue(unit)
+	if value != q.Cursor.Value {
+		return value < q.Cursor.Value
+	}
+
+	return bytes.Compare(unit.Id[:], q.Cursor.Id[:]) < 0
+}
+
+// Will find a page of the user's units matching the query. The stats of each unit are calculated.
+// The next cursor is empty if there are no more units.
+func FindUnitsPage(ctx context.Context, db *pgxpool.Pool, dc *DataCache, userId uuid.UUID, q UnitQuery) ([]Unit, string, error) {
+	units := make([]Unit, 0)
+
+	where := []string{"user_id = $1"}
+	args := []interface{}{userId}
+
+	filter := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(condition, len(args)))
+	}
+
+	if q.Template != nil {
+		filter("template = $%v", *q.Template)
+	}
+
+	if q.Type != nil {
+		templates := make([]int, 0)
+		for _, template := range dc.UnitTemplates {
+			if template.TypeID == *q.Type {
+				templates = append(templates, template.ID)
+			}
+		}
+
+		filter("template = ANY($%v)", templates)
+	}
+
+	if q.Stars != nil {
+		filter("stars = $%v", *q.Stars)
+	}
+
+	if q.Locked != nil {
+		filter("is_locked = $%v", *q.Locked)
+	}
+
+	// power is calculated in go so the whole roster must be sorted and paginated after the query
+	inDatabase := q.Sort != UNIT_SORT_POWER
+	order := ""
+
+	if inDatabase {
+		if q.Cursor != nil {
+			args = append(args, q.Cursor.Value, q.Cursor.Id)
+			where = append(where, fmt.Sprintf("(%v, id) < ($%v, $%v)", q.Sort, len(args)-1, len(args)))
+		}
+
+		order = fmt.Sprintf(" ORDER BY %v DESC, id DESC LIMIT %v", q.Sort, UNITS_PAGE_SIZE+1)
+	}
+
+	query := "SELECT " + UNIT_COLUMNS + " FROM units WHERE (" + strings.Join(where, " AND ") + ")" + order
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return units, "", fmt.Errorf("fail to query units table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var unit Unit
+
+		if err := rows.Scan(unit.ScanTargets()...); err != nil {
+			return units, "", fmt.Errorf("fail to scan into unit: %w", err)
+		}
+
+		unit.Stats = CalcUnitStats(dc, unit)
+
+		if inDatabase || q.IsAfterCursor(unit) {
+			units = append(units, unit)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return units, "", err
+	}
+
+	if !inDatabase {
+		sort.Slice(units, func(i, j int) bool {
+			a, b := q.SortValue(units[i]), q.SortValue(units[j])
+			if a != b {
+				return a > b
+			}
+
+			return bytes.Compare(units[i].Id[:], units[j].Id[:]) > 0
+		})
+	}
+
+	if len(units) <= UNITS_PAGE_SIZE {
+		return units, "", nil
+	}
+
+	units = units[:UNITS_PAGE_SIZE]
+	last := units[len(units)-1]
+
+	return units, UnitCursor{Value: q.SortValue(last), Id: last.Id}.String(), nil
+}
@@ -0,0 +1,38 @@
+package main
+
+// The final stats of a unit after applying its level and stars to the template's base stats.
+type UnitStats struct {
+	Hp    int `json:"hp"`
+	Atk   int `json:"atk"`
+	Def   int `json:"def"`
+	Spd   int `json:"spd"`
+	Power int `json:"power"` // A single number used to compare the strength of units.
+}
+
+// Will calculate the unit's stats. Every level adds UNIT_STAT_GROWTH percent and every star past the first
+// adds UNIT_STAR_STAT_GROWTH percent of the template's base stats. Equipped items add flat bonuses.
+func CalcUnitStats(dc *DataCache, unit Unit) UnitStats {
+	template, _ := FindUnitTemplate(dc, unit.Template)
+
+	percent := 100 + (unit.Level-1)*UNIT_STAT_GROWTH + (unit.Stars-1)*UNIT_STAR_STAT_GROWTH
+
+	stats := UnitStats{
+		Hp:  template.Hp * percent / 100,
+		Atk: template.Atk * percent / 100,
+		Def: template.Def * percent / 100,
+		Spd: template.Spd * percent / 100,
+	}
+
+	for _, id := range unit.Items {
+		if item, ok := FindItemTemplate(dc, id); ok {
+			stats.Hp += item.Hp
+			stats.Atk += item.Atk
+			stats.Def += item.Def
+			stats.Spd += item.Spd
+		}
+	}
+
+	stats.Power = stats.Hp + stats.Atk*2 + stats.Def*2 + stats.Spd
+
+	return stats
+}
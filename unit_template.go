@@ -5,13 +5,15 @@ import (
 )
 
 type UnitTemplate struct {
-	ID     int    `json:"id"`
-	TypeID int    `json:"typeId"`
-	Name   string `json:"name"`
-	Hp     int    `json:"hp"`
-	Atk    int    `json:"atk"`
-	Def    int    `json:"def"`
-	Spd    int    `json:"spd"`
+	ID     int     `json:"id"`
+	TypeID int     `json:"typeId"`
+	Rarity int     `json:"rarity"`
+	Name   string  `json:"name"`
+	Hp     int     `json:"hp"`
+	Atk    int     `json:"atk"`
+	Def    int     `json:"def"`
+	Spd    int     `json:"spd"`
+	Skills []Skill `json:"skills"`
 }
 
 func UnMarshalUnitTemplatesJson() ([]UnitTemplate, error) {
@@ -25,9 +27,13 @@ func UnMarshalUnitTemplatesJson() ([]UnitTemplate, error) {
 	return data["unitTemplates"], nil
 }
 
-// Return a random unit template ID.
-func RandUnitTemplateID(dc *DataCache) int {
-	count := len(dc.UnitTemplates)
+// Find the unit template with the given ID. The bool will be false if the template doesn't exist.
+func FindUnitTemplate(dc *DataCache, id int) (UnitTemplate, bool) {
+	for _, template := range dc.UnitTemplates {
+		if template.ID == id {
+			return template, true
+		}
+	}
 
-	return RandInt(0, count)
+	return UnitTemplate{}, false
 }
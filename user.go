@@ -62,6 +62,10 @@ func InsertUser(ctx context.Context, tx pgx.Tx, dc *DataCache, user User) error
 		return err
 	}
 
+	if err := InsertPrestige(ctx, tx, user.Id); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -85,6 +89,11 @@ func InsertAdminUser(ctx context.Context, db *pgxpool.Pool, dataCache *DataCache
 				return fmt.Errorf("fail to insert user: %w", err)
 			}
 
+			// the admin flag is only ever set here, registered users can never become admin
+			if _, err := tx.Exec(ctx, "UPDATE users SET is_admin = true WHERE id = $1", user.Id); err != nil {
+				return fmt.Errorf("fail to set admin flag: %w", err)
+			}
+
 			// give admin user some resources to make testing easier
 			for _, resource := range dataCache.Resources {
 				if err := IncResource(ctx, tx, user.Id, resource.Type, 100000); err != nil {
@@ -105,6 +114,18 @@ func InsertAdminUser(ctx context.Context, db *pgxpool.Pool, dataCache *DataCache
 	return nil
 }
 
+// Returns true if the user has the admin flag set.
+func IsAdminUser(ctx context.Context, db *pgxpool.Pool, userId uuid.UUID) (bool, error) {
+	var isAdmin bool
+
+	err := db.QueryRow(ctx, "SELECT is_admin FROM users WHERE id = $1", userId).Scan(&isAdmin)
+	if err != nil {
+		return false, fmt.Errorf("fail to query users table: %w", err)
+	}
+
+	return isAdmin, nil
+}
+
 func IncUserExp(ctx context.Context, tx pgx.Tx, userId uuid.UUID, amount int) error {
 	query := "UPDATE users SET exp = exp + $1 WHERE id = $2"
 